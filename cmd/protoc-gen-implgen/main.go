@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/not-for-prod/implgen/protoc"
+)
+
+// main runs implgen as a protoc plugin, reading CodeGeneratorRequest from stdin
+// and writing CodeGeneratorResponse to stdout.
+// Example:
+//
+//	protoc --implgen_out=. --implgen_opt=impl-name=Service,kind=stub,kind=middleware greeter.proto
+func main() {
+	if err := protoc.NewCommand().Execute(os.Stdin, os.Stdout); err != nil {
+		// stdout is reserved for the plugin response
+		_, _ = fmt.Fprintf(os.Stderr, "protoc-gen-implgen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the config file looked up in the working directory when no source is set
+const DefaultPath = ".implgen.yaml"
+
+// Target describes a single implgen invocation, field names match the CLI flags.
+// Unset fields are taken from the config defaults, see Target.Merge
+type Target struct {
+	Src                       string   `yaml:"src"`
+	Dst                       string   `yaml:"dst"`
+	InterfaceName             string   `yaml:"interface-name"`
+	ImplementationName        string   `yaml:"impl-name"`
+	ImplementationPackageName string   `yaml:"impl-package"`
+	SingleFile                *bool    `yaml:"single-file"`
+	Kinds                     []string `yaml:"kinds"`
+	MultiSelect               string   `yaml:"multi-select"`
+	GRPCSrc                   string   `yaml:"grpc-src"`
+	GRPCServer                string   `yaml:"grpc-server"`
+	Deps                      []string `yaml:"deps"`
+	DepNilCheck               *bool    `yaml:"dep-nil-check"`
+	Constructor               string   `yaml:"constructor"`
+	// Overwrite is the policy for existing files, see writer.Overwrite* constants
+	Overwrite string `yaml:"overwrite"`
+}
+
+// Config is the project configuration listing generation targets
+type Config struct {
+	// Defaults are applied to every target
+	Defaults Target   `yaml:"defaults"`
+	Targets  []Target `yaml:"targets"`
+}
+
+// Load reads the config file, source and destination paths are resolved relative to its directory
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err = dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	if len(cfg.Targets) == 0 {
+		return Config{}, fmt.Errorf("%s has no targets", path)
+	}
+
+	dir := filepath.Dir(path)
+
+	cfg.Defaults = cfg.Defaults.relative(dir)
+	for i := range cfg.Targets {
+		cfg.Targets[i] = cfg.Targets[i].relative(dir)
+	}
+
+	return cfg, nil
+}
+
+// Resolve returns the targets with defaults applied
+func (cfg Config) Resolve() []Target {
+	targets := make([]Target, 0, len(cfg.Targets))
+	for _, target := range cfg.Targets {
+		targets = append(targets, cfg.Defaults.Merge(target))
+	}
+
+	return targets
+}
+
+// Merge returns t with the fields set in overlay replacing its own, lists are replaced as a whole
+func (t Target) Merge(overlay Target) Target {
+	set(&t.Src, overlay.Src)
+	set(&t.Dst, overlay.Dst)
+	set(&t.InterfaceName, overlay.InterfaceName)
+	set(&t.ImplementationName, overlay.ImplementationName)
+	set(&t.ImplementationPackageName, overlay.ImplementationPackageName)
+	set(&t.SingleFile, overlay.SingleFile)
+	set(&t.Kinds, overlay.Kinds)
+	set(&t.MultiSelect, overlay.MultiSelect)
+	set(&t.GRPCSrc, overlay.GRPCSrc)
+	set(&t.GRPCServer, overlay.GRPCServer)
+	set(&t.Deps, overlay.Deps)
+	set(&t.DepNilCheck, overlay.DepNilCheck)
+	set(&t.Constructor, overlay.Constructor)
+	set(&t.Overwrite, overlay.Overwrite)
+
+	return t
+}
+
+// Validate reports missing required fields and settings requiring others
+func (t Target) Validate() error {
+	if t.Src == "" {
+		return errors.New("src is required")
+	}
+	if t.Dst == "" {
+		return errors.New("dst is required")
+	}
+	if t.ImplementationPackageName != "" && t.InterfaceName == "" {
+		return errors.New("impl-package requires interface-name to be set")
+	}
+
+	return nil
+}
+
+// relative resolves the target paths relative to dir
+func (t Target) relative(dir string) Target {
+	for _, path := range []*string{&t.Src, &t.Dst, &t.GRPCSrc} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(dir, *path)
+		}
+	}
+
+	return t
+}
+
+// set replaces dst with value unless value is the zero one
+func set[T any](dst *T, value T) {
+	if !reflect.ValueOf(&value).Elem().IsZero() {
+		*dst = value
+	}
+}
@@ -0,0 +1,194 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/config"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestTargetMerge(t *testing.T) {
+	base := config.Target{
+		Src:           "a.go",
+		Dst:           "out",
+		InterfaceName: "A",
+		SingleFile:    ptr(true),
+		Kinds:         []string{"stub", "funcs"},
+		Deps:          []string{"log=*slog.Logger"},
+		DepNilCheck:   ptr(true),
+		Overwrite:     "never",
+	}
+
+	tests := []struct {
+		name    string
+		overlay config.Target
+		want    config.Target
+	}{
+		{
+			name:    "empty overlay",
+			overlay: config.Target{},
+			want:    base,
+		},
+		{
+			name: "set fields replace",
+			overlay: config.Target{
+				Dst:                "gen",
+				ImplementationName: "Impl",
+				Overwrite:          "always",
+			},
+			want: config.Target{
+				Src:                "a.go",
+				Dst:                "gen",
+				InterfaceName:      "A",
+				ImplementationName: "Impl",
+				SingleFile:         ptr(true),
+				Kinds:              []string{"stub", "funcs"},
+				Deps:               []string{"log=*slog.Logger"},
+				DepNilCheck:        ptr(true),
+				Overwrite:          "always",
+			},
+		},
+		{
+			name: "lists replace as a whole",
+			overlay: config.Target{
+				Kinds: []string{"multi"},
+				Deps:  []string{"repo=ports.Repo"},
+			},
+			want: config.Target{
+				Src:           "a.go",
+				Dst:           "out",
+				InterfaceName: "A",
+				SingleFile:    ptr(true),
+				Kinds:         []string{"multi"},
+				Deps:          []string{"repo=ports.Repo"},
+				DepNilCheck:   ptr(true),
+				Overwrite:     "never",
+			},
+		},
+		{
+			name: "set false booleans replace",
+			overlay: config.Target{
+				SingleFile:  ptr(false),
+				DepNilCheck: ptr(false),
+			},
+			want: config.Target{
+				Src:           "a.go",
+				Dst:           "out",
+				InterfaceName: "A",
+				SingleFile:    ptr(false),
+				Kinds:         []string{"stub", "funcs"},
+				Deps:          []string{"log=*slog.Logger"},
+				DepNilCheck:   ptr(false),
+				Overwrite:     "never",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.Merge(tt.overlay); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  config.Target
+		wantErr string
+	}{
+		{name: "valid", target: config.Target{Src: "a.go", Dst: "out"}},
+		{name: "no src", target: config.Target{Dst: "out"}, wantErr: "src is required"},
+		{name: "no dst", target: config.Target{Src: "a.go"}, wantErr: "dst is required"},
+		{
+			name:    "impl package without interface",
+			target:  config.Target{Src: "a.go", Dst: "out", ImplementationPackageName: "impl"},
+			wantErr: "impl-package requires interface-name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.target.Validate()
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, config.DefaultPath)
+	data := `defaults:
+  dst: gen
+  kinds: [stub, funcs]
+targets:
+  - src: ports/user.go
+    interface-name: UserRepo
+  - src: /abs/order.go
+    interface-name: OrderRepo
+    dst: order
+    kinds: [multi]
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := []config.Target{
+		{
+			Src:           filepath.Join(dir, "ports", "user.go"),
+			Dst:           filepath.Join(dir, "gen"),
+			InterfaceName: "UserRepo",
+			Kinds:         []string{"stub", "funcs"},
+		},
+		{
+			Src:           "/abs/order.go",
+			Dst:           filepath.Join(dir, "order"),
+			InterfaceName: "OrderRepo",
+			Kinds:         []string{"multi"},
+		},
+	}
+	if got := cfg.Resolve(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolve() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "empty", data: "", wantErr: "has no targets"},
+		{name: "unknown field", data: "targets:\n  - src: a.go\n    templates: tmpl\n", wantErr: "field templates not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), config.DefaultPath)
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := config.Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FromDirective builds the target generating the interface declared in src from the
+// name=value arguments of its `//implgen:generate` directive. Names match the target fields,
+// name and package are short for impl-name and impl-package, lists are comma-separated
+// and dep can be repeated. Paths are relative to the src directory, like go:generate ones
+func FromDirective(src, interfaceName string, args []string) (Target, error) {
+	target := Target{InterfaceName: interfaceName}
+
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return Target{}, fmt.Errorf("invalid argument %q, expected name=value", arg)
+		}
+
+		var err error
+
+		switch name {
+		case "dst":
+			target.Dst = value
+		case "name", "impl-name":
+			target.ImplementationName = value
+		case "package", "impl-package":
+			target.ImplementationPackageName = value
+		case "single-file":
+			target.SingleFile, err = parseBool(value)
+		case "kind", "kinds":
+			target.Kinds = strings.Split(value, ",")
+		case "multi-select":
+			target.MultiSelect = value
+		case "grpc-src":
+			target.GRPCSrc = value
+		case "grpc-server":
+			target.GRPCServer = value
+		case "dep":
+			target.Deps = append(target.Deps, value)
+		case "dep-nil-check":
+			target.DepNilCheck, err = parseBool(value)
+		case "constructor":
+			target.Constructor = value
+		case "overwrite":
+			target.Overwrite = value
+		default:
+			return Target{}, fmt.Errorf("unknown argument %q", name)
+		}
+
+		if err != nil {
+			return Target{}, fmt.Errorf("invalid argument %q: %w", arg, err)
+		}
+	}
+
+	// src is set after resolving the other paths relative to it, it isn't relative to itself
+	target = target.relative(filepath.Dir(src))
+	target.Src = src
+
+	return target, nil
+}
+
+func parseBool(value string) (*bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
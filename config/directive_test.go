@@ -0,0 +1,85 @@
+package config_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/config"
+)
+
+func TestFromDirective(t *testing.T) {
+	src := filepath.Join("ports", "user.go")
+
+	tests := []struct {
+		name string
+		args []string
+		want config.Target
+	}{
+		{
+			name: "no arguments",
+			want: config.Target{Src: src, InterfaceName: "UserRepo"},
+		},
+		{
+			name: "paths relative to src",
+			args: []string{"dst=../impl", "grpc-src=/abs/user.proto"},
+			want: config.Target{Src: src, InterfaceName: "UserRepo", Dst: "impl", GRPCSrc: "/abs/user.proto"},
+		},
+		{
+			name: "short and long names",
+			args: []string{"name=Repo", "package=repo", "kind=stub,funcs"},
+			want: config.Target{
+				Src:                       src,
+				InterfaceName:             "UserRepo",
+				ImplementationName:        "Repo",
+				ImplementationPackageName: "repo",
+				Kinds:                     []string{"stub", "funcs"},
+			},
+		},
+		{
+			name: "repeated deps and booleans",
+			args: []string{"dep=log=*slog.Logger", "dep=limit=int", "dep-nil-check=true", "single-file=false"},
+			want: config.Target{
+				Src:           src,
+				InterfaceName: "UserRepo",
+				Deps:          []string{"log=*slog.Logger", "limit=int"},
+				DepNilCheck:   ptr(true),
+				SingleFile:    ptr(false),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := config.FromDirective(src, "UserRepo", tt.args)
+			if err != nil {
+				t.Fatalf("FromDirective() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromDirective() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromDirectiveErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "no value", args: []string{"dst"}, wantErr: `invalid argument "dst", expected name=value`},
+		{name: "unknown name", args: []string{"templates=tmpl"}, wantErr: `unknown argument "templates"`},
+		{name: "invalid boolean", args: []string{"single-file=maybe"}, wantErr: `invalid argument "single-file=maybe"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.FromDirective("user.go", "UserRepo", tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FromDirective() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,7 +4,7 @@ import (
 	context "context"
 	_ "embed"
 
-	in "github.com/not-for-prod/implgen/."
+	in "github.com/not-for-prod/implgen/example/in"
 )
 
 func (i *Test) F(ctx context.Context, req in.FRequest) (in.FResponse, error) {
@@ -2,11 +2,15 @@ package test
 
 import (
 	_ "embed"
+
+	in "github.com/not-for-prod/implgen/example/in"
 )
 
 type Test struct {
 }
 
+var _ in.TestInterface = (*Test)(nil)
+
 func NewTest() *Test {
 	return &Test{}
 }
@@ -2,11 +2,15 @@ package test_interface
 
 import (
 	_ "embed"
+
+	in "github.com/not-for-prod/implgen/example/in"
 )
 
 type Implementation struct {
 }
 
+var _ in.TestInterface = (*Implementation)(nil)
+
 func NewImplementation() *Implementation {
 	return &Implementation{}
 }
@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// generateBenchmarks generates benchmark skeletons for the KindStub implementation methods,
+// following the singleFile layout of KindTest.
+func (cmd *Command) generateBenchmarks(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{{Path: "context"}, {Path: "testing"}}
+
+	return cmd.generateTestFiles(pkg, ifce, "_bench_test.go", imports, ifce.Methods, cmd.generateBenchmark)
+}
+
+// generateBenchmark writes the benchmark of a single implementation method.
+func (cmd *Command) generateBenchmark(g *protogen.GeneratedFile, names map[string]string, method model.Method) {
+	// the parameters are declared as locals next to b and i
+	local := newScope([]model.Method{method})
+	b, i := local.declare("b"), local.declare("i")
+
+	g.P("func Benchmark", cmd.implementationName, "_", method.Name, "(", b, " *", names["testing"], ".B) {")
+	g.P(i, " := &", cmd.implementationName, "{}")
+	for _, param := range method.In {
+		if param.Kind == model.KindContext {
+			g.P(paramName(param), " := ", names["context"], ".Background()")
+		} else {
+			g.P("var ", param.Name, " ", fieldType(param), " // TODO: set the argument")
+		}
+	}
+	g.P()
+	g.P("for range ", b, ".N {")
+	g.P(i, ".", method.Name, generateArgs(method.In))
+	g.P("}")
+	g.P("}")
+	g.P()
+}
@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+)
+
+func TestGenerateBenchmarks(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindStub, KindBench), parseExample(t), nil)
+
+	bench, ok := generated["e_bench_test.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate e_bench_test.go")
+	}
+
+	for _, want := range []string{
+		"func BenchmarkImplementation_E(b *testing.B) {",
+		"ctx := context.Background()",
+		"var req in.ERequest // TODO: set the argument",
+		"for range b.N {",
+		"i.E(ctx, req)",
+	} {
+		if !strings.Contains(bench, want) {
+			t.Errorf("e_bench_test.go lacks %q:\n%s", want, bench)
+		}
+	}
+}
+
+func TestGenerateFuzzTests(t *testing.T) {
+	pkg := model.Package{
+		Name: "store",
+		Imports: []model.Import{
+			{Alias: "context", Path: "context"},
+			{Alias: "store", Path: "example.com/store"},
+		},
+		Interfaces: []model.Interface{{
+			Name: "Store",
+			Methods: []model.Method{
+				{
+					Name: "Put",
+					In: []model.Parameter{
+						{Name: "ctx", Type: "context.Context", Kind: model.KindContext},
+						{Name: "id", Type: "store.ID", Kind: model.KindInt, Basic: "int64"},
+						{Name: "data", Type: "[]byte", Kind: model.KindBytes, Basic: "[]byte"},
+						{Name: "force", Type: "bool", Kind: model.KindBool, Basic: "bool"},
+					},
+					Out: []model.Parameter{{Name: "result0", Type: "error"}},
+				},
+				{
+					// not fuzzable
+					Name: "Watch",
+					In:   []model.Parameter{{Name: "ch", Type: "chan store.ID"}},
+				},
+			},
+		}},
+	}
+	stubs := map[string]string{"example.com/store": `package store
+
+import "context"
+
+type ID int64
+
+type Store interface {
+	Put(ctx context.Context, id ID, data []byte, force bool) error
+	Watch(ch chan ID)
+}
+`}
+
+	cmd := newExampleCommand(KindStub, KindFuzz)
+	cmd.interfaceName = "Store"
+
+	generated := generateExample(t, cmd, pkg, stubs)
+
+	if _, ok := generated["watch_fuzz_test.go"]; ok {
+		t.Error("Execute() generated watch_fuzz_test.go for a method taking a channel")
+	}
+
+	fuzz, ok := generated["put_fuzz_test.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate put_fuzz_test.go")
+	}
+
+	for _, want := range []string{
+		"func FuzzImplementation_Put(f *testing.F) {",
+		`f.Add(int64(0), []byte(""), false)`,
+		`f.Add(int64(1), []byte("fuzz"), true)`,
+		"f.Fuzz(func(t *testing.T, id int64, data []byte, force bool) {",
+		"i.Put(context.Background(), store.ID(id), data, force)",
+	} {
+		if !strings.Contains(fuzz, want) {
+			t.Errorf("put_fuzz_test.go lacks %q:\n%s", want, fuzz)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package generator
+
+import (
+	"github.com/not-for-prod/implgen/model"
+	stringCase "github.com/not-for-prod/implgen/pkg/string-case"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// flagFuncs maps primitive parameter types to the pflag.FlagSet methods binding them,
+// parameters of other types are read as JSON.
+var flagFuncs = map[string]string{
+	"string":   "StringVar",
+	"bool":     "BoolVar",
+	"int":      "IntVar",
+	"int8":     "Int8Var",
+	"int16":    "Int16Var",
+	"int32":    "Int32Var",
+	"int64":    "Int64Var",
+	"uint":     "UintVar",
+	"uint8":    "Uint8Var",
+	"uint16":   "Uint16Var",
+	"uint32":   "Uint32Var",
+	"uint64":   "Uint64Var",
+	"float32":  "Float32Var",
+	"float64":  "Float64Var",
+	"[]string": "StringSliceVar",
+	"[]int":    "IntSliceVar",
+}
+
+// flagDefaults holds zero values of the types bound by flagFuncs.
+var flagDefaults = map[string]string{
+	"string":   `""`,
+	"bool":     "false",
+	"[]string": "nil",
+	"[]int":    "nil",
+}
+
+// cliCommandName returns the name of the function creating the method subcommand.
+func cliCommandName(method model.Method) string {
+	return "command" + method.Name
+}
+
+// generateCLI generates the cobra front-end of the interface: a command tree with one
+// subcommand per method, primitive parameters bound to kebab-case flags, complex ones
+// read as JSON from a flag or stdin and results printed as JSON.
+func (cmd *Command) generateCLI(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{{Path: "encoding/json"}, {Path: "github.com/spf13/cobra"}}
+
+	file, err := cmd.generateFile(
+		pkg, ifce, "cli.go", imports, func(g *protogen.GeneratedFile, names map[string]string) {
+			typ := interfaceType(pkg, ifce)
+			cobra, json := names["github.com/spf13/cobra"], names["encoding/json"]
+
+			g.P("// NewCLI", ifce.Name, " returns a cobra command exposing ", typ, " methods as subcommands.")
+			g.P("func NewCLI", ifce.Name, "(impl ", typ, ") *", cobra, ".Command {")
+			g.P("cmd := &", cobra, ".Command{")
+			g.P("Use: \"", stringCase.KebabCase(ifce.Name), "\",")
+			g.P("Short: \"Calls ", typ, " methods\",")
+			g.P("}")
+			for _, method := range ifce.Methods {
+				g.P("cmd.AddCommand(", cliCommandName(method), "(impl))")
+			}
+			g.P()
+			g.P("return cmd")
+			g.P("}")
+			g.P()
+
+			for _, method := range ifce.Methods {
+				generateCLICommand(g, cobra, typ, method)
+			}
+
+			g.P("// decodeJSON decodes the JSON flag value into v, `-` reads it from stdin.")
+			g.P("func decodeJSON(cmd *", cobra, ".Command, value string, v any) error {")
+			g.P("switch value {")
+			g.P("case \"\":")
+			g.P("return nil")
+			g.P("case \"-\":")
+			g.P("return ", json, ".NewDecoder(cmd.InOrStdin()).Decode(v)")
+			g.P("default:")
+			g.P("return ", json, ".Unmarshal([]byte(value), v)")
+			g.P("}")
+			g.P("}")
+			g.P()
+			g.P("// printJSON prints v encoded as JSON.")
+			g.P("func printJSON(cmd *", cobra, ".Command, v any) error {")
+			g.P("enc := ", json, ".NewEncoder(cmd.OutOrStdout())")
+			g.P("enc.SetIndent(\"\", \"  \")")
+			g.P()
+			g.P("return enc.Encode(v)")
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
+
+// generateCLICommand writes the function creating the subcommand calling the method,
+// cobra is the name of the imported cobra package.
+func generateCLICommand(g *protogen.GeneratedFile, cobra, typ string, method model.Method) {
+	params := requestParams(method)
+
+	g.P("// ", cliCommandName(method), " returns the subcommand calling ", typ, ".", method.Name, ".")
+	g.P("func ", cliCommandName(method), "(impl ", typ, ") *", cobra, ".Command {")
+	if len(params) > 0 {
+		g.P("var req ", requestName(method))
+		for _, param := range params {
+			if _, ok := flagFuncs[fieldType(param)]; !ok {
+				g.P("var ", param.Name, "JSON string")
+			}
+		}
+		g.P()
+	}
+	g.P("cmd := &", cobra, ".Command{")
+	g.P("Use: \"", stringCase.KebabCase(method.Name), "\",")
+	g.P("Short: \"Calls ", typ, ".", method.Name, "\",")
+	g.P("Args: ", cobra, ".NoArgs,")
+	g.P("RunE: func(cmd *", cobra, ".Command, args []string) error {")
+	for _, param := range params {
+		if _, ok := flagFuncs[fieldType(param)]; !ok {
+			g.P("if err := decodeJSON(cmd, ", param.Name, "JSON, &req.", fieldName(param), "); err != nil {")
+			g.P("return err")
+			g.P("}")
+		}
+	}
+	g.P()
+	if len(responseResults(method)) > 0 {
+		g.P("var res ", responseName(method))
+	}
+	generateCall(g, method, "impl", "req", "cmd.Context()", "res")
+	if returnsError(method) {
+		g.P("if err != nil {")
+		g.P("return err")
+		g.P("}")
+	}
+	g.P()
+	if len(responseResults(method)) > 0 {
+		g.P("return printJSON(cmd, res)")
+	} else {
+		g.P("return nil")
+	}
+	g.P("},")
+	g.P("}")
+
+	for _, param := range params {
+		flag := stringCase.KebabCase(param.Name)
+
+		if flagFunc, ok := flagFuncs[fieldType(param)]; ok {
+			value, ok := flagDefaults[fieldType(param)]
+			if !ok {
+				value = "0"
+			}
+
+			g.P("cmd.Flags().", flagFunc, "(&req.", fieldName(param), ", \"", flag, "\", ", value, ", \"", param.Name, " parameter\")")
+		} else {
+			g.P("cmd.Flags().StringVar(&", param.Name, "JSON, \"", flag, "\", \"\", \"", param.Name, " parameter as JSON, - reads it from stdin\")")
+		}
+	}
+	g.P()
+	g.P("return cmd")
+	g.P("}")
+	g.P()
+}
@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+)
+
+func TestGenerateCLI(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindCLI), parseExample(t), nil)
+
+	cli, ok := generated["cli.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate cli.go")
+	}
+
+	for _, want := range []string{
+		"func NewCLITestInterface(impl in.TestInterface) *cobra.Command {",
+		"cmd.AddCommand(commandE(impl))",
+		`Use:   "e",`,
+		"res.Result0, err = impl.E(cmd.Context(), req.Req)",
+		`cmd.Flags().StringVar(&reqJSON, "req", "", "req parameter as JSON, - reads it from stdin")`,
+	} {
+		if !strings.Contains(cli, want) {
+			t.Errorf("cli.go lacks %q:\n%s", want, cli)
+		}
+	}
+}
+
+func TestGenerateCLIUnnamed(t *testing.T) {
+	// parameters and results as the parser names unnamed and blank ones
+	pkg := model.Package{
+		Name: "store",
+		Imports: []model.Import{
+			{Alias: "context", Path: "context"},
+			{Alias: "store", Path: "example.com/store"},
+		},
+		Interfaces: []model.Interface{{
+			Name: "Store",
+			Methods: []model.Method{{
+				Name: "Get",
+				In: []model.Parameter{
+					{Name: "ctx", Type: "context.Context", Kind: model.KindContext},
+					{Name: "arg1", Type: "string", Kind: model.KindString, Basic: "string"},
+				},
+				Out: []model.Parameter{{Name: "result0", Type: "int"}, {Name: "result1", Type: "error"}},
+			}},
+		}},
+	}
+	stubs := map[string]string{"example.com/store": `package store
+
+import "context"
+
+type Store interface {
+	Get(context.Context, string) (int, error)
+}
+`}
+
+	cmd := newExampleCommand(KindCLI)
+	cmd.interfaceName = "Store"
+
+	generated := generateExample(t, cmd, pkg, stubs)
+
+	for file, wants := range map[string][]string{
+		"cli.go": {
+			"res.Result0, err = impl.Get(cmd.Context(), req.Arg1)",
+			`cmd.Flags().StringVar(&req.Arg1, "arg1", "", "arg1 parameter")`,
+		},
+		"messages.go": {
+			"Arg1 string `json:\"arg1\"`",
+			"Result0 int `json:\"result0\"`",
+		},
+	} {
+		for _, want := range wants {
+			if !strings.Contains(generated[file], want) {
+				t.Errorf("%s lacks %q:\n%s", file, want, generated[file])
+			}
+		}
+	}
+}
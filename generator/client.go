@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// clientName returns the name of the HTTP client struct for the interface.
+func clientName(ifce model.Interface) string {
+	return "Client" + ifce.Name
+}
+
+// generateClient generates the typed HTTP/JSON client: a struct implementing the interface
+// by POSTing the request struct of every method to the KindHTTP handlers and decoding
+// the response struct or the Error envelope.
+func (cmd *Command) generateClient(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{
+		{Path: "bytes"},
+		{Path: "context"},
+		{Path: "encoding/json"},
+		{Path: "fmt"},
+		{Path: "net/http"},
+		{Path: "strings"},
+	}
+
+	file, err := cmd.generateFile(
+		pkg, ifce, "client.go", imports, func(g *protogen.GeneratedFile, names map[string]string) {
+			name := clientName(ifce)
+			http, json := names["net/http"], names["encoding/json"]
+			recv := newScope(ifce.Methods).declare("c")
+
+			g.P("// ", name, " implements ", interfaceType(pkg, ifce), " by calling its HTTP handlers, see NewHTTP", ifce.Name, ".")
+			g.P("// Methods without an error result panic when the call fails.")
+			g.P("type ", name, " struct {")
+			g.P("// BaseURL is the URL the handlers are served on, e.g. http://localhost:8080/api.")
+			g.P("BaseURL string")
+			g.P()
+			g.P("// HTTPClient sends the requests, http.DefaultClient is used when it isn't set.")
+			g.P("HTTPClient *", http, ".Client")
+			g.P("}")
+			g.P()
+
+			for _, method := range ifce.Methods {
+				generateClientMethod(g, names, name, recv, method, httpRoute(method))
+			}
+
+			g.P("// call posts req to the route and decodes the response into res.")
+			g.P("func (", recv, " ", name, ") call(ctx ", names["context"], ".Context, route string, req, res any) error {")
+			g.P("body, err := ", json, ".Marshal(req)")
+			g.P("if err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P()
+			g.P("url := ", names["strings"], ".TrimSuffix(", recv, ".BaseURL, \"/\") + route")
+			g.P(
+				"httpReq, err := ", http, ".NewRequestWithContext(ctx, ", http, ".MethodPost, url, ",
+				names["bytes"], ".NewReader(body))",
+			)
+			g.P("if err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P("httpReq.Header.Set(\"Content-Type\", \"application/json\")")
+			g.P()
+			g.P("client := ", recv, ".HTTPClient")
+			g.P("if client == nil {")
+			g.P("client = ", http, ".DefaultClient")
+			g.P("}")
+			g.P()
+			g.P("httpRes, err := client.Do(httpReq)")
+			g.P("if err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P("defer func() { _ = httpRes.Body.Close() }()")
+			g.P()
+			g.P("if httpRes.StatusCode != ", http, ".StatusOK {")
+			g.P("e := &Error{}")
+			g.P("if err = ", json, ".NewDecoder(httpRes.Body).Decode(e); err != nil {")
+			g.P("return ", names["fmt"], ".Errorf(\"unexpected response status %s\", httpRes.Status)")
+			g.P("}")
+			g.P()
+			g.P("return e")
+			g.P("}")
+			g.P()
+			g.P("return ", json, ".NewDecoder(httpRes.Body).Decode(res)")
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
+
+// generateClientMethod writes a client method sending the request struct built from
+// the method parameters to the target via the recv call method and returning the results from the response struct.
+func generateClientMethod(
+	g *protogen.GeneratedFile,
+	names map[string]string,
+	name, recv string,
+	method model.Method,
+	target string,
+) {
+	local := newScope([]model.Method{method}, recv)
+	resp, err := local.declare("resp"), local.declare("err")
+
+	g.P("func (", recv, " ", name, ") ", method.Name, generateParams(method.In), " ", generateResults(method.Out), " {")
+	g.P("var ", resp, " ", responseName(method))
+	g.P(
+		err, " := ", recv, ".call(", contextArg(method, names), ", \"", target, "\", ",
+		generateRequest(method), ", &", resp, ")",
+	)
+
+	if !returnsError(method) {
+		g.P("if ", err, " != nil {")
+		g.P("panic(", err, ")")
+		g.P("}")
+	}
+
+	results := make([]string, 0, len(method.Out))
+	for _, result := range responseResults(method) {
+		results = append(results, resp+"."+fieldName(result))
+	}
+	if returnsError(method) {
+		results = append(results, err)
+	}
+
+	if len(results) > 0 {
+		g.P()
+		g.P("return ", strings.Join(results, ", "))
+	}
+	g.P("}")
+	g.P()
+}
+
+// generateRequest builds the request struct literal from the method parameters.
+func generateRequest(method model.Method) string {
+	fields := make([]string, 0, len(method.In))
+
+	for _, param := range requestParams(method) {
+		fields = append(fields, fieldName(param)+": "+paramName(param))
+	}
+
+	return requestName(method) + "{" + strings.Join(fields, ", ") + "}"
+}
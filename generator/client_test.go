@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+)
+
+func TestGenerateClient(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindHTTP, KindClient), parseExample(t), nil)
+
+	client, ok := generated["client.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate client.go")
+	}
+
+	for _, want := range []string{
+		"type ClientTestInterface struct {",
+		"var resp EResponse",
+		`err := c.call(ctx, "/e", ERequest{Req: req}, &resp)`,
+		"return resp.Result0, err",
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("client.go lacks %q:\n%s", want, client)
+		}
+	}
+}
+
+func TestGenerateCollisions(t *testing.T) {
+	// parameters are named like the receivers and locals generated methods declare
+	pkg := model.Package{
+		Name: "collide",
+		Imports: []model.Import{
+			{Alias: "context", Path: "context"},
+			{Alias: "collide", Path: "example.com/collide"},
+		},
+		Interfaces: []model.Interface{{
+			Name: "Collide",
+			Methods: []model.Method{
+				{
+					Name: "Fetch",
+					In: []model.Parameter{
+						{Name: "ctx", Type: "context.Context", Kind: model.KindContext},
+						{Name: "resp", Type: "string", Kind: model.KindString, Basic: "string"},
+						{Name: "c", Type: "int", Kind: model.KindInt, Basic: "int"},
+						{Name: "s", Type: "int", Kind: model.KindInt, Basic: "int"},
+						{Name: "f", Type: "int", Kind: model.KindInt, Basic: "int"},
+						{Name: "b", Type: "int", Kind: model.KindInt, Basic: "int"},
+						{Name: "t", Type: "int", Kind: model.KindInt, Basic: "int"},
+						{Name: "i", Type: "int", Kind: model.KindInt, Basic: "int"},
+						{Name: "impl", Type: "int", Kind: model.KindInt, Basic: "int"},
+					},
+					Out: []model.Parameter{{Name: "n", Type: "int"}, {Name: "err", Type: "error"}},
+				},
+				{
+					Name: "Send",
+					In: []model.Parameter{
+						{Name: "err", Type: "string", Kind: model.KindString, Basic: "string"},
+						{Name: "m", Type: "int", Kind: model.KindInt, Basic: "int"},
+					},
+					Out: []model.Parameter{{Name: "result0", Type: "error"}},
+				},
+			},
+		}},
+	}
+	stubs := map[string]string{"example.com/collide": `package collide
+
+import "context"
+
+type Collide interface {
+	Fetch(ctx context.Context, resp string, c, s, f, b, t, i, impl int) (n int, err error)
+	Send(err string, m int) error
+}
+`}
+
+	kinds := []string{
+		KindStub, KindFuncs, KindMulti, KindFallback, KindSwitch, KindHTTP, KindClient, KindRPC,
+		KindContract, KindBench, KindFuzz,
+	}
+	for _, kind := range kinds {
+		t.Run(kind, func(t *testing.T) {
+			cmd := newExampleCommand(KindStub, kind)
+			cmd.interfaceName = "Collide"
+
+			generateExample(t, cmd, pkg, stubs)
+		})
+	}
+}
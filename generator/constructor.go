@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	stringCase "github.com/not-for-prod/implgen/pkg/string-case"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+const (
+	// ConstructorPositional makes KindStub constructors take dependencies as parameters.
+	ConstructorPositional = "positional"
+	// ConstructorOptions makes KindStub constructors take functional options setting dependencies.
+	ConstructorOptions = "options"
+)
+
+// dependencies returns the interface Deps overridden and extended by the configured ones.
+func (cmd *Command) dependencies(ifce model.Interface) []model.Dependency {
+	deps := append([]model.Dependency(nil), ifce.Deps...)
+
+	for _, dep := range cmd.deps {
+		i := slices.IndexFunc(deps, func(d model.Dependency) bool { return d.Name == dep.Name })
+		if i >= 0 {
+			deps[i] = dep
+		} else {
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps
+}
+
+// nilChecked reports whether the constructor checks any of the dependencies for nil.
+func (cmd *Command) nilChecked(deps []model.Dependency) bool {
+	return cmd.depNilCheck && slices.ContainsFunc(deps, func(dep model.Dependency) bool { return dep.Nillable })
+}
+
+// generateConstructor writes the implementation constructor in the configured style,
+// with depNilCheck it returns an error when a nillable dependency is nil.
+func (cmd *Command) generateConstructor(
+	g *protogen.GeneratedFile,
+	names map[string]string,
+	deps []model.Dependency,
+) error {
+	switch cmd.constructor {
+	case "", ConstructorPositional:
+		cmd.generatePositionalConstructor(g, names, deps)
+	case ConstructorOptions:
+		cmd.generateOptionsConstructor(g, names, deps)
+	default:
+		return fmt.Errorf("unknown constructor style %q", cmd.constructor)
+	}
+
+	return nil
+}
+
+// constructorResults returns the constructor results, including error when dependencies are nil-checked.
+func (cmd *Command) constructorResults(deps []model.Dependency) string {
+	if cmd.nilChecked(deps) {
+		return "(*" + cmd.implementationName + ", error)"
+	}
+
+	return "*" + cmd.implementationName
+}
+
+// generateNilChecks writes the nil checks of the nillable dependencies accessed via prefix.
+func (cmd *Command) generateNilChecks(
+	g *protogen.GeneratedFile,
+	names map[string]string,
+	deps []model.Dependency,
+	prefix string,
+) {
+	if !cmd.nilChecked(deps) {
+		return
+	}
+
+	for _, dep := range deps {
+		if dep.Nillable {
+			g.P("if ", prefix, dep.Name, " == nil {")
+			g.P("return nil, ", names["errors"], ".New(\"", dep.Name, " is nil\")")
+			g.P("}")
+		}
+	}
+	g.P()
+}
+
+// generatePositionalConstructor writes the constructor taking the dependencies as parameters.
+func (cmd *Command) generatePositionalConstructor(
+	g *protogen.GeneratedFile,
+	names map[string]string,
+	deps []model.Dependency,
+) {
+	params := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		params = append(params, dep.Name+" "+dep.Type)
+	}
+
+	g.P("func New", cmd.implementationName, "(", strings.Join(params, ", "), ") ", cmd.constructorResults(deps), " {")
+	cmd.generateNilChecks(g, names, deps, "")
+
+	suffix := ""
+	if cmd.nilChecked(deps) {
+		suffix = ", nil"
+	}
+
+	if len(deps) == 0 {
+		g.P("return &", cmd.implementationName, "{}", suffix)
+	} else {
+		g.P("return &", cmd.implementationName, "{")
+		for _, dep := range deps {
+			g.P(dep.Name, ": ", dep.Name, ",")
+		}
+		g.P("}", suffix)
+	}
+	g.P("}")
+}
+
+// generateOptionsConstructor writes the Option type, a WithXxx option per dependency
+// and the constructor applying options over the dependency defaults.
+func (cmd *Command) generateOptionsConstructor(
+	g *protogen.GeneratedFile,
+	names map[string]string,
+	deps []model.Dependency,
+) {
+	g.P("// Option configures ", cmd.implementationName, ".")
+	g.P("type Option func(*", cmd.implementationName, ")")
+	g.P()
+
+	for _, dep := range deps {
+		g.P("// With", stringCase.PascalCase(dep.Name), " sets the ", dep.Name, " dependency.")
+		impl := newScope(nil, dep.Name).declare("impl")
+
+		g.P("func With", stringCase.PascalCase(dep.Name), "(", dep.Name, " ", dep.Type, ") Option {")
+		g.P("return func(", impl, " *", cmd.implementationName, ") {")
+		g.P(impl, ".", dep.Name, " = ", dep.Name)
+		g.P("}")
+		g.P("}")
+		g.P()
+	}
+
+	g.P("func New", cmd.implementationName, "(opts ...Option) ", cmd.constructorResults(deps), " {")
+	g.P("impl := &", cmd.implementationName, "{")
+	for _, dep := range deps {
+		if dep.Default != "" {
+			g.P(dep.Name, ": ", dep.Default, ",")
+		}
+	}
+	g.P("}")
+	g.P("for _, opt := range opts {")
+	g.P("opt(impl)")
+	g.P("}")
+	g.P()
+	cmd.generateNilChecks(g, names, deps, "impl.")
+
+	if cmd.nilChecked(deps) {
+		g.P("return impl, nil")
+	} else {
+		g.P("return impl")
+	}
+	g.P("}")
+}
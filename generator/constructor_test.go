@@ -0,0 +1,210 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+)
+
+// depsStubs are sources of the packages of the testDeps types outside of the module
+var depsStubs = map[string]string{"example.com/ports": `package ports
+
+type UserRepo interface {
+	Get(id int) (string, error)
+}
+`}
+
+// testDeps returns dependencies of every kind of type: imported by path, qualified and builtin
+func testDeps(t *testing.T) []model.Dependency {
+	t.Helper()
+
+	deps := make([]model.Dependency, 0, 3)
+	for _, spec := range []string{
+		"repo=example.com/ports.UserRepo",
+		"logger=*log.Logger=log.Default()",
+		"limit=int=10",
+	} {
+		dep, err := model.ParseDependency(spec)
+		if err != nil {
+			t.Fatalf("ParseDependency(%q) error = %v", spec, err)
+		}
+		deps = append(deps, dep)
+	}
+
+	return deps
+}
+
+// newDepsCommand returns the Command generating KindStub of TestInterface with testDeps
+func newDepsCommand(t *testing.T, constructor string, depNilCheck bool) *Command {
+	t.Helper()
+
+	return NewCommand(
+		"out",
+		"TestInterface",
+		"Implementation",
+		"",
+		false,
+		[]string{KindStub},
+		MultiSelectFirst,
+		GRPCServer{},
+		testDeps(t),
+		depNilCheck,
+		constructor,
+	)
+}
+
+// withLog returns the example package importing log, as the source of *log.Logger would
+func withLog(pkg model.Package) model.Package {
+	pkg.Imports = append(append([]model.Import(nil), pkg.Imports...), model.Import{Alias: "log", Path: "log"})
+
+	return pkg
+}
+
+func TestGeneratePositionalConstructor(t *testing.T) {
+	tests := []struct {
+		name        string
+		depNilCheck bool
+		want        []string
+		notWant     []string
+	}{
+		{
+			name: "no nil checks",
+			want: []string{
+				"\trepo   ports.UserRepo\n",
+				"\tlogger *log.Logger\n",
+				"\tlimit  int\n",
+				"func NewImplementation(repo ports.UserRepo, logger *log.Logger, limit int) *Implementation {",
+				"\t\trepo:   repo,\n",
+			},
+			notWant: []string{"== nil"},
+		},
+		{
+			name:        "nil checks",
+			depNilCheck: true,
+			want: []string{
+				"func NewImplementation(repo ports.UserRepo, logger *log.Logger, limit int) (*Implementation, error) {",
+				"if repo == nil {",
+				`return nil, errors.New("repo is nil")`,
+				"if logger == nil {",
+				"}, nil",
+			},
+			notWant: []string{"if limit == nil {"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newDepsCommand(t, ConstructorPositional, tt.depNilCheck)
+			generated := generateExample(t, cmd, withLog(parseExample(t)), depsStubs)
+
+			implementation := generated["implementation.go"]
+			for _, want := range tt.want {
+				if !strings.Contains(implementation, want) {
+					t.Errorf("implementation.go lacks %q:\n%s", want, implementation)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(implementation, notWant) {
+					t.Errorf("implementation.go has %q:\n%s", notWant, implementation)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateDependencyOverrides(t *testing.T) {
+	pkg := withLog(parseExample(t))
+	pkg.Interfaces = append([]model.Interface(nil), pkg.Interfaces...)
+	for i := range pkg.Interfaces {
+		pkg.Interfaces[i].Deps = []model.Dependency{
+			{Name: "limit", Type: "int64"},
+			{Name: "name", Type: "string"},
+		}
+	}
+
+	generated := generateExample(t, newDepsCommand(t, ConstructorPositional, false), pkg, depsStubs)
+
+	// configured dependencies override the declared ones of the same name and follow the others
+	want := "func NewImplementation(limit int, name string, repo ports.UserRepo, logger *log.Logger) *Implementation {"
+	if !strings.Contains(generated["implementation.go"], want) {
+		t.Errorf("implementation.go lacks %q:\n%s", want, generated["implementation.go"])
+	}
+}
+
+func TestGenerateOptionsConstructor(t *testing.T) {
+	tests := []struct {
+		name        string
+		depNilCheck bool
+		want        []string
+	}{
+		{
+			name: "no nil checks",
+			want: []string{
+				"type Option func(*Implementation)",
+				"func WithRepo(repo ports.UserRepo) Option {",
+				"func WithLogger(logger *log.Logger) Option {",
+				"\t\timpl.logger = logger\n",
+				"func NewImplementation(opts ...Option) *Implementation {",
+				"\t\tlogger: log.Default(),\n",
+				"\t\tlimit:  10,\n",
+				"\treturn impl\n",
+			},
+		},
+		{
+			name:        "nil checks",
+			depNilCheck: true,
+			want: []string{
+				"func NewImplementation(opts ...Option) (*Implementation, error) {",
+				"if impl.repo == nil {",
+				`return nil, errors.New("repo is nil")`,
+				"return impl, nil",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newDepsCommand(t, ConstructorOptions, tt.depNilCheck)
+			generated := generateExample(t, cmd, withLog(parseExample(t)), depsStubs)
+
+			implementation := generated["implementation.go"]
+			for _, want := range tt.want {
+				if !strings.Contains(implementation, want) {
+					t.Errorf("implementation.go lacks %q:\n%s", want, implementation)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateOptionsConstructorImplDependency(t *testing.T) {
+	cmd := NewCommand(
+		"out",
+		"TestInterface",
+		"Implementation",
+		"",
+		false,
+		[]string{KindStub},
+		MultiSelectFirst,
+		GRPCServer{},
+		[]model.Dependency{{Name: "impl", Type: "string"}},
+		false,
+		ConstructorOptions,
+	)
+
+	generated := generateExample(t, cmd, parseExample(t), nil)
+
+	// the option receiver doesn't shadow the dependency it sets
+	want := "\t\timpl1.impl = impl\n"
+	if !strings.Contains(generated["implementation.go"], want) {
+		t.Errorf("implementation.go lacks %q:\n%s", want, generated["implementation.go"])
+	}
+}
+
+func TestGenerateUnknownConstructor(t *testing.T) {
+	_, err := newDepsCommand(t, "builder", false).Execute(parseExample(t))
+	if err == nil || !strings.Contains(err.Error(), `unknown constructor style "builder"`) {
+		t.Errorf("Execute() error = %v, want the unknown constructor style error", err)
+	}
+}
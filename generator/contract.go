@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// generateContract generates the reusable contract test suite of the interface: an exported
+// RunXxxContract harness with one subtest per method, so that every implementation package
+// can run the same behavioral suite against its own implementation.
+func (cmd *Command) generateContract(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{{Path: "context"}, {Path: "testing"}}
+
+	file, err := cmd.generateFile(
+		pkg, ifce, "contract.go", imports, func(g *protogen.GeneratedFile, names map[string]string) {
+			typ := interfaceType(pkg, ifce)
+
+			g.P("// Run", ifce.Name, "Contract runs the behavioral suite every ", typ, " implementation")
+			g.P("// must pass, newImpl is called by every subtest to get a fresh implementation.")
+			g.P("func Run", ifce.Name, "Contract(t *", names["testing"], ".T, newImpl func(t *", names["testing"], ".T) ", typ, ") {")
+			g.P("t.Helper()")
+			g.P()
+
+			for i, method := range ifce.Methods {
+				if i > 0 {
+					g.P()
+				}
+				generateContractMethod(g, names, method)
+			}
+
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
+
+// generateContractMethod writes the subtest of a single method calling it with
+// zero arguments and leaving TODO assertions on its results.
+func generateContractMethod(g *protogen.GeneratedFile, names map[string]string, method model.Method) {
+	// the parameters are declared as locals next to t, impl and err
+	local := newScope([]model.Method{method})
+	t, impl := local.declare("t"), local.declare("impl")
+
+	g.P("t.Run(\"", method.Name, "\", func(", t, " *", names["testing"], ".T) {")
+	g.P(impl, " := newImpl(", t, ")")
+	g.P()
+
+	for _, param := range method.In {
+		if param.Type == "context.Context" {
+			g.P(paramName(param), " := ", names["context"], ".Background()")
+		} else {
+			g.P("var ", param.Name, " ", fieldType(param), " // TODO: set the argument")
+		}
+	}
+	if len(method.In) > 0 {
+		g.P()
+	}
+
+	values := make([]string, 0, len(method.Out))
+	for _, result := range responseResults(method) {
+		values = append(values, result.Name)
+	}
+
+	results := values
+	err := ""
+	if returnsError(method) {
+		err = local.declare("err")
+		results = append(results, err)
+	}
+
+	call := impl + "." + method.Name + generateArgs(method.In)
+	if len(results) > 0 {
+		call = strings.Join(results, ", ") + " := " + call
+	}
+	g.P(call)
+
+	if returnsError(method) {
+		g.P("if ", err, " != nil {")
+		g.P(t, ".Fatalf(\"", method.Name, "() error = %v\", ", err, ")")
+		g.P("}")
+	}
+
+	g.P()
+	g.P("// TODO: assert the behavior.")
+	for _, value := range values {
+		g.P("_ = ", value)
+	}
+	g.P("})")
+}
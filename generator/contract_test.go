@@ -0,0 +1,27 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateContract(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindContract), parseExample(t), nil)
+
+	contract, ok := generated["contract.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate contract.go")
+	}
+
+	for _, want := range []string{
+		"func RunTestInterfaceContract(t *testing.T, newImpl func(t *testing.T) in.TestInterface) {",
+		`t.Run("E", func(t *testing.T) {`,
+		"impl := newImpl(t)",
+		"result0, err := impl.E(ctx, req)",
+		`t.Fatalf("E() error = %v", err)`,
+	} {
+		if !strings.Contains(contract, want) {
+			t.Errorf("contract.go lacks %q:\n%s", want, contract)
+		}
+	}
+}
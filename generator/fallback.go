@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// fallbackName returns the name of the failover struct for the interface.
+func fallbackName(ifce model.Interface) string {
+	return "Fallback" + ifce.Name
+}
+
+// generateFallback generates the failover implementation: a struct calling the primary
+// implementation and falling back to the secondary one when the primary returns an error
+// matching a configurable predicate. Methods without an error result always use the primary.
+func (cmd *Command) generateFallback(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	file, err := cmd.generateFile(
+		pkg, ifce, "fallback.go", nil, func(g *protogen.GeneratedFile, _ map[string]string) {
+			name := fallbackName(ifce)
+			typ := interfaceType(pkg, ifce)
+			recv := newScope(ifce.Methods).declare("f")
+
+			g.P("// ", name, " calls Primary and falls back to Secondary when it fails.")
+			g.P("type ", name, " struct {")
+			g.P("Primary   ", typ)
+			g.P("Secondary ", typ)
+			g.P()
+			g.P("// ShouldFallback reports whether an error returned by Primary triggers the fallback,")
+			g.P("// any non-nil error does when it isn't set.")
+			g.P("ShouldFallback func(err error) bool")
+			g.P()
+			g.P("// OnFallback is called before falling back to Secondary, e.g. for logging or metrics.")
+			g.P("OnFallback func(method string, err error)")
+			g.P("}")
+			g.P()
+
+			for _, method := range ifce.Methods {
+				g.P("func (", recv, " ", name, ") ", method.Name, generateParams(method.In), " ", generateResults(method.Out), " {")
+				if !returnsError(method) {
+					if len(method.Out) > 0 {
+						g.P("return ", recv, ".Primary.", method.Name, generateArgs(method.In))
+					} else {
+						g.P(recv, ".Primary.", method.Name, generateArgs(method.In))
+					}
+					g.P("}")
+					g.P()
+					continue
+				}
+
+				// result names are reserved by the scope, err is declared unless it's a result name too
+				local := newScope([]model.Method{method}, recv)
+				err := local.declare("err")
+
+				results := make([]string, 0, len(method.Out))
+				for _, result := range method.Out[:len(method.Out)-1] {
+					results = append(results, result.Name)
+				}
+				results = append(results, err)
+
+				g.P(strings.Join(results, ", "), " := ", recv, ".Primary.", method.Name, generateArgs(method.In))
+				g.P("if !", recv, ".fallback(\"", method.Name, "\", ", err, ") {")
+				g.P("return ", strings.Join(results, ", "))
+				g.P("}")
+				g.P()
+				g.P("return ", recv, ".Secondary.", method.Name, generateArgs(method.In))
+				g.P("}")
+				g.P()
+			}
+
+			g.P("// fallback reports whether a call failed with err must be retried on Secondary.")
+			g.P("func (", recv, " ", name, ") fallback(method string, err error) bool {")
+			g.P("if err == nil || ", recv, ".ShouldFallback != nil && !", recv, ".ShouldFallback(err) {")
+			g.P("return false")
+			g.P("}")
+			g.P()
+			g.P("if ", recv, ".OnFallback != nil {")
+			g.P(recv, ".OnFallback(method, err)")
+			g.P("}")
+			g.P()
+			g.P("return true")
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
@@ -0,0 +1,28 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateFallback(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindFallback), parseExample(t), nil)
+
+	fallback, ok := generated["fallback.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate fallback.go")
+	}
+
+	for _, want := range []string{
+		"type FallbackTestInterface struct {",
+		"ShouldFallback func(err error) bool",
+		"OnFallback func(method string, err error)",
+		"result0, err := f.Primary.E(ctx, req)",
+		`if !f.fallback("E", err) {`,
+		"return f.Secondary.E(ctx, req)",
+	} {
+		if !strings.Contains(fallback, want) {
+			t.Errorf("fallback.go lacks %q:\n%s", want, fallback)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// funcsName returns the name of the Funcs adapter struct for the interface.
+func funcsName(ifce model.Interface) string {
+	return ifce.Name + "Funcs"
+}
+
+// generateFuncs generates the Funcs adapter: a struct with one func field per method
+// and methods delegating to those fields. Methods whose field is nil return
+// a configurable "not implemented" error, or panic with it when they can't return errors.
+func (cmd *Command) generateFuncs(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{{Path: "errors"}, {Path: "fmt"}}
+
+	file, err := cmd.generateFile(
+		pkg, ifce, "funcs.go", imports, func(g *protogen.GeneratedFile, names map[string]string) {
+			name := funcsName(ifce)
+			recv := newScope(ifce.Methods).declare("f")
+
+			g.P("// ErrNotImplemented is returned by ", name, " methods whose func field is nil.")
+			g.P("var ErrNotImplemented = ", names["errors"], ".New(\"not implemented\")")
+			g.P()
+			g.P("// ", name, " implements ", interfaceType(pkg, ifce), " by calling the func field of every method.")
+			g.P("type ", name, " struct {")
+			for _, method := range ifce.Methods {
+				g.P(method.Name, "Func ", generateSignature(method))
+			}
+			g.P()
+			g.P("// NotImplemented builds the error for a method whose func field is nil,")
+			g.P("// ErrNotImplemented wrapped with the method name is used when it isn't set.")
+			g.P("NotImplemented func(method string) error")
+			g.P("}")
+			g.P()
+
+			for _, method := range ifce.Methods {
+				g.P("func (", recv, " ", name, ") ", method.Name, generateParams(method.In), " ", generateResults(method.Out), " {")
+				g.P("if ", recv, ".", method.Name, "Func == nil {")
+				if returnsError(method) {
+					generateZeroReturn(g, method.Out, recv+".notImplemented(\""+method.Name+"\")")
+				} else {
+					g.P("panic(", recv, ".notImplemented(\"", method.Name, "\"))")
+				}
+				g.P("}")
+				g.P()
+				if len(method.Out) > 0 {
+					g.P("return ", recv, ".", method.Name, "Func", generateArgs(method.In))
+				} else {
+					g.P(recv, ".", method.Name, "Func", generateArgs(method.In))
+				}
+				g.P("}")
+				g.P()
+			}
+
+			g.P("func (", recv, " ", name, ") notImplemented(method string) error {")
+			g.P("if ", recv, ".NotImplemented != nil {")
+			g.P("return ", recv, ".NotImplemented(method)")
+			g.P("}")
+			g.P()
+			g.P("return ", names["fmt"], ".Errorf(\"", name, ".%s: %w\", method, ErrNotImplemented)")
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+)
+
+// clashPackage is the parsed package of a Store interface importing packages named like
+// the standard ones generated code uses, stubs are their sources
+var (
+	clashPackage = model.Package{
+		Name: "clash",
+		Imports: []model.Import{
+			{Alias: "context", Path: "context"},
+			{Alias: "errors", Path: "example.com/clash/errors"},
+			{Alias: "fmt", Path: "example.com/clash/fmt"},
+			{Alias: "clash", Path: "example.com/clash"},
+		},
+		Interfaces: []model.Interface{{
+			Name: "Store",
+			Methods: []model.Method{{
+				Name: "Get",
+				In: []model.Parameter{
+					{Name: "ctx", Type: "context.Context", Kind: model.KindContext},
+					{Name: "e", Type: "errors.T"},
+					{Name: "f", Type: "fmt.T"},
+				},
+				Out: []model.Parameter{{Name: "result0", Type: "errors.T"}, {Name: "err", Type: "error"}},
+			}},
+		}},
+	}
+	clashStubs = map[string]string{
+		"example.com/clash/errors": "package errors\n\ntype T struct{}\n",
+		"example.com/clash/fmt":    "package fmt\n\ntype T struct{}\n",
+		"example.com/clash": `package clash
+
+import (
+	"context"
+
+	"example.com/clash/errors"
+	"example.com/clash/fmt"
+)
+
+type Store interface {
+	Get(ctx context.Context, e errors.T, f fmt.T) (errors.T, error)
+}
+`,
+	}
+)
+
+// newClashCommand returns the Command generating kinds of the clashPackage Store interface into out
+func newClashCommand(kinds ...string) *Command {
+	cmd := newExampleCommand(kinds...)
+	cmd.interfaceName = "Store"
+
+	return cmd
+}
+
+func TestGenerateFuncs(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindFuncs), parseExample(t), nil)
+
+	funcs, ok := generated["funcs.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate funcs.go")
+	}
+
+	for _, want := range []string{
+		"type TestInterfaceFuncs struct {",
+		"AFunc func(ctx context.Context, req dto.GoRequest) error",
+		"NotImplemented func(method string) error",
+	} {
+		if !strings.Contains(funcs, want) {
+			t.Errorf("funcs.go lacks %q:\n%s", want, funcs)
+		}
+	}
+}
+
+func TestGenerateFuncsImportClash(t *testing.T) {
+	generated := generateExample(t, newClashCommand(KindFuncs), clashPackage, clashStubs)
+
+	funcs := generated["funcs.go"]
+	for _, want := range []string{`stderrors "errors"`, `stdfmt "fmt"`, "stderrors.New(", "stdfmt.Errorf("} {
+		if !strings.Contains(funcs, want) {
+			t.Errorf("funcs.go lacks %q:\n%s", want, funcs)
+		}
+	}
+}
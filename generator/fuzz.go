@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// fuzzSeeds holds the seed corpus values of fuzzable basic types, types missing here are
+// numeric and seeded with converted 0 and 1.
+var fuzzSeeds = map[string][]string{
+	"string": {`""`, `"fuzz"`},
+	"[]byte": {`[]byte("")`, `[]byte("fuzz")`},
+	"bool":   {"false", "true"},
+}
+
+// fuzzable reports whether the method takes fuzzable parameters only, besides the context.
+func fuzzable(method model.Method) bool {
+	fuzzed := false
+
+	for _, param := range method.In {
+		switch {
+		case param.Kind == model.KindContext:
+		case param.Kind.Fuzzable():
+			fuzzed = true
+		default:
+			return false
+		}
+	}
+
+	return fuzzed
+}
+
+// generateFuzzTests generates fuzz test skeletons for the KindStub implementation methods
+// taking fuzzable primitives only, following the singleFile layout of KindTest.
+func (cmd *Command) generateFuzzTests(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{{Path: "context"}, {Path: "testing"}}
+
+	methods := make([]model.Method, 0, len(ifce.Methods))
+	for _, method := range ifce.Methods {
+		if fuzzable(method) {
+			methods = append(methods, method)
+		}
+	}
+
+	return cmd.generateTestFiles(pkg, ifce, "_fuzz_test.go", imports, methods, cmd.generateFuzzTest)
+}
+
+// generateFuzzTest writes the fuzz test of a single implementation method,
+// the fuzz target takes underlying basic types converted to the parameter types.
+func (cmd *Command) generateFuzzTest(g *protogen.GeneratedFile, names map[string]string, method model.Method) {
+	params := make([]string, 0, len(method.In))
+	args := make([]string, 0, len(method.In))
+	seeds := make([][]string, 2)
+
+	for _, param := range method.In {
+		if param.Kind == model.KindContext {
+			args = append(args, names["context"]+".Background()")
+			continue
+		}
+
+		params = append(params, param.Name+" "+param.Basic)
+
+		if param.Type == param.Basic {
+			args = append(args, param.Name)
+		} else {
+			args = append(args, param.Type+"("+param.Name+")")
+		}
+
+		values, ok := fuzzSeeds[param.Basic]
+		if !ok {
+			values = []string{param.Basic + "(0)", param.Basic + "(1)"}
+		}
+		for i := range seeds {
+			seeds[i] = append(seeds[i], values[i])
+		}
+	}
+
+	g.P("func Fuzz", cmd.implementationName, "_", method.Name, "(f *", names["testing"], ".F) {")
+	for _, seed := range seeds {
+		g.P("f.Add(", strings.Join(seed, ", "), ")")
+	}
+	g.P()
+	// the fuzz target takes the parameters next to t and i
+	local := newScope([]model.Method{method})
+	t, i := local.declare("t"), local.declare("i")
+
+	g.P("f.Fuzz(func(", t, " *", names["testing"], ".T, ", strings.Join(params, ", "), ") {")
+	g.P(i, " := &", cmd.implementationName, "{}")
+	g.P(i, ".", method.Name, "(", strings.Join(args, ", "), ")")
+	g.P()
+	g.P("// TODO: assert the invariants.")
+	g.P("})")
+	g.P("}")
+	g.P()
+}
@@ -2,7 +2,9 @@ package generator
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/not-for-prod/implgen/model"
@@ -30,6 +32,26 @@ type Command struct {
 	// singleFile determines whether all methods should be generated into a single file.
 	// If false, each method will be written into its own file.
 	singleFile bool
+
+	// kinds lists the kinds of output generated for every interface, see Kind* constants.
+	kinds []string
+
+	// multiSelect is the policy choosing which delegate results are returned by KindMulti
+	// methods, see MultiSelect* constants. If empty, such methods are rejected.
+	multiSelect string
+
+	// grpcServer is the protoc-generated service KindGRPC adapters are generated against.
+	grpcServer GRPCServer
+
+	// deps are fields of KindStub implementations set by their constructors,
+	// they override the interface Deps of the same name.
+	deps []model.Dependency
+
+	// depNilCheck makes KindStub constructors return an error on nil dependencies.
+	depNilCheck bool
+
+	// constructor is the style of KindStub constructors, see Constructor* constants.
+	constructor string
 }
 
 // NewCommand creates a new Command with the given parameters.
@@ -40,6 +62,12 @@ func NewCommand(
 	implementationName string,
 	implementationPackageName string,
 	singleFile bool,
+	kinds []string,
+	multiSelect string,
+	grpcServer GRPCServer,
+	deps []model.Dependency,
+	depNilCheck bool,
+	constructor string,
 ) *Command {
 	return &Command{
 		dst:                       dst,
@@ -47,6 +75,12 @@ func NewCommand(
 		implementationName:        implementationName,
 		implementationPackageName: implementationPackageName,
 		singleFile:                singleFile,
+		kinds:                     kinds,
+		multiSelect:               multiSelect,
+		grpcServer:                grpcServer,
+		deps:                      deps,
+		depNilCheck:               depNilCheck,
+		constructor:               constructor,
 	}
 }
 
@@ -76,18 +110,25 @@ func (cmd *Command) dstPath(ifce model.Interface) string {
 	return filepath.Join(cmd.dst, cmd.folderName(ifce))
 }
 
-// Execute creates basic implementations for all interfaces in the provided package.
+// Execute creates the configured kinds of output for all interfaces in the provided package.
 // If a specific interface name is configured, only that one is processed.
 func (cmd *Command) Execute(pkg model.Package) ([]model.File, error) {
 	files := make([]model.File, 0)
 
+	kinds, err := resolveKinds(cmd.kinds)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, _interface := range pkg.Interfaces {
 		if cmd.interfaceName == "" || cmd.interfaceName == _interface.Name {
-			file, err := cmd.generateInterface(pkg, _interface)
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate interface for %s, err: %w", _interface.Name, err)
+			for _, kind := range kinds {
+				file, err := kindGenerators[kind](cmd, pkg, _interface)
+				if err != nil {
+					return nil, fmt.Errorf("failed to generate %s for %s, err: %w", kind, _interface.Name, err)
+				}
+				files = append(files, file...)
 			}
-			files = append(files, file...)
 		}
 	}
 
@@ -102,19 +143,37 @@ func (cmd *Command) generateInterface(pkg model.Package, ifce model.Interface) (
 	g := p.NewGeneratedFile("", "")
 	files := make([]model.File, 0)
 
-	cmd.generateHeader(g, pkg, ifce)
+	deps := cmd.dependencies(ifce)
+
+	imports := []model.Import{{Path: "errors"}}
+	for _, dep := range deps {
+		if dep.Import.Path != "" {
+			imports = append(imports, dep.Import)
+		}
+	}
+
+	cmd.generateHeader(g, pkg, ifce, imports...)
+	names := importNames(pkg, imports...)
 
 	g.P("type ", cmd.implementationName, " struct {")
+	for _, embed := range ifce.Embeds {
+		g.P(embed)
+	}
+	for _, dep := range deps {
+		g.P(dep.Name, " ", dep.Type)
+	}
 	g.P("}")
 	g.P()
-	g.P("func New", cmd.implementationName, "() *", cmd.implementationName, " {")
-	g.P("return &", cmd.implementationName, "{}")
-	g.P("}")
+	g.P("var _ ", interfaceType(pkg, ifce), " = (*", cmd.implementationName, ")(nil)")
+	g.P()
+	if err := cmd.generateConstructor(g, names, deps); err != nil {
+		return nil, err
+	}
 
 	for _, method := range ifce.Methods {
 		if cmd.singleFile {
 			g.P()
-			cmd.generateMethod(g, method)
+			cmd.generateMethod(g, newScope(ifce.Methods).declare("i"), method)
 		} else {
 			file, err := cmd.generateMethodFile(pkg, ifce, method)
 			if err != nil {
@@ -141,23 +200,124 @@ func (cmd *Command) generateInterface(pkg model.Package, ifce model.Interface) (
 }
 
 // generateHeader writes the file header including package declaration and imports.
-func (cmd *Command) generateHeader(g *protogen.GeneratedFile, pkg model.Package, ifce model.Interface) {
+// Extra imports are written along with the source package ones.
+func (cmd *Command) generateHeader(
+	g *protogen.GeneratedFile,
+	pkg model.Package,
+	ifce model.Interface,
+	extra ...model.Import,
+) {
 	g.P("package ", cmd.packageName(ifce))
 	g.P()
-	generateImports(g, pkg)
+	generateImports(g, pkg, extra...)
 	g.P()
 }
 
 // generateImports writes import statements for a given package,
-// including all user-defined imports and a default one for OpenTelemetry.
-func generateImports(g *protogen.GeneratedFile, pkg model.Package) {
+// including all user-defined imports and the extra ones not imported by the package yet,
+// aliased when their names are taken, see importNames.
+func generateImports(g *protogen.GeneratedFile, pkg model.Package, extra ...model.Import) {
+	names := importNames(pkg, extra...)
+	written := make(map[string]bool, len(pkg.Imports)+len(extra))
+
 	g.P("import (")
 	for _, _import := range pkg.Imports {
 		g.P(_import.Alias, " \"", _import.Path, "\"")
+		// blank and dot imports can't be referred to, extra imports of the same path are still written
+		written[_import.Path] = written[_import.Path] || _import.Alias != "_" && _import.Alias != "."
+	}
+	for _, _import := range extra {
+		if written[_import.Path] {
+			continue
+		}
+		written[_import.Path] = true
+
+		alias := _import.Alias
+		if name := names[_import.Path]; name != path.Base(_import.Path) {
+			alias = name
+		}
+		g.P(alias, " \"", _import.Path, "\"")
 	}
 	g.P(")")
 }
 
+// importNames returns the names generated code refers to imported packages by, keyed by import path:
+// aliases of the package imports and base names of the extra ones. Extra imports whose names are
+// taken by other imports are aliased, with the std prefix for the standard library packages
+// and numbered when it is taken too.
+func importNames(pkg model.Package, extra ...model.Import) map[string]string {
+	names := make(map[string]string, len(pkg.Imports)+len(extra))
+	taken := make(map[string]bool, len(pkg.Imports)+len(extra))
+
+	for _, _import := range pkg.Imports {
+		if _import.Alias == "_" || _import.Alias == "." {
+			continue
+		}
+		if _, ok := names[_import.Path]; !ok {
+			names[_import.Path] = _import.Alias
+		}
+		taken[_import.Alias] = true
+	}
+
+	for _, _import := range extra {
+		if _, ok := names[_import.Path]; ok {
+			continue
+		}
+
+		name := _import.Alias
+		if name == "" {
+			name = path.Base(_import.Path)
+		}
+
+		alias := name
+		if taken[alias] && !strings.Contains(strings.Split(_import.Path, "/")[0], ".") {
+			alias = "std" + name
+		}
+		for i := 1; taken[alias]; i++ {
+			alias = name + strconv.Itoa(i)
+		}
+
+		names[_import.Path] = alias
+		taken[alias] = true
+	}
+
+	return names
+}
+
+// scope holds identifiers declared by a generated function,
+// so that receivers and locals it declares don't collide with the method parameters.
+type scope map[string]bool
+
+// newScope returns the scope holding parameter and result names of the methods, along with names.
+func newScope(methods []model.Method, names ...string) scope {
+	s := make(scope)
+
+	for _, method := range methods {
+		for _, param := range method.In {
+			s[paramName(param)] = true
+		}
+		for _, result := range method.Out {
+			s[result.Name] = true
+		}
+	}
+	for _, name := range names {
+		s[name] = true
+	}
+
+	return s
+}
+
+// declare declares name, numbered when it is taken, and returns the declared one.
+func (s scope) declare(name string) string {
+	declared := name
+	for i := 1; s[declared]; i++ {
+		declared = name + strconv.Itoa(i)
+	}
+	s[declared] = true
+
+	return declared
+}
+
 // generateMethodFile generates a standalone Go file containing a single method implementation stub.
 func (cmd *Command) generateMethodFile(
 	pkg model.Package,
@@ -168,7 +328,7 @@ func (cmd *Command) generateMethodFile(
 	g := p.NewGeneratedFile("", "")
 
 	cmd.generateHeader(g, pkg, ifce)
-	cmd.generateMethod(g, method)
+	cmd.generateMethod(g, newScope(ifce.Methods).declare("i"), method)
 
 	content, err := g.Content()
 	if err != nil {
@@ -186,12 +346,12 @@ func (cmd *Command) generateMethodFile(
 	}, nil
 }
 
-// generateMethod writes the method implementation stub to the provided generated file.
-func (cmd *Command) generateMethod(g *protogen.GeneratedFile, method model.Method) {
+// generateMethod writes the method implementation stub with the recv receiver to the provided generated file.
+func (cmd *Command) generateMethod(g *protogen.GeneratedFile, recv string, method model.Method) {
 	params := generateParams(method.In)
 	results := generateResults(method.Out)
 
-	g.P("func (i *", cmd.implementationName, ")", method.Name, " ", params, " ", results, "{")
+	g.P("func (", recv, " *", cmd.implementationName, ")", method.Name, " ", params, " ", results, "{")
 	g.P("panic(\"implement me\")")
 	g.P("}")
 	g.P()
@@ -203,11 +363,7 @@ func generateParams(params []model.Parameter) string {
 	b.WriteString("(")
 
 	for i, param := range params {
-		if param.Type == "context.Context" {
-			b.WriteString("ctx")
-		} else {
-			b.WriteString(param.Name)
-		}
+		b.WriteString(paramName(param))
 
 		b.WriteString(" ")
 		b.WriteString(param.Type)
@@ -242,3 +398,79 @@ func generateResults(results []model.Parameter) string {
 
 	return b.String()
 }
+
+// generateArgs builds a call argument list forwarding the parameters declared by generateParams.
+func generateArgs(params []model.Parameter) string {
+	b := strings.Builder{}
+	b.WriteString("(")
+
+	for i, param := range params {
+		b.WriteString(paramName(param))
+		if strings.HasPrefix(param.Type, "...") {
+			b.WriteString("...")
+		}
+		if i != len(params)-1 {
+			b.WriteString(", ")
+		}
+	}
+
+	b.WriteString(")")
+	return b.String()
+}
+
+// generateSignature builds a func type literal matching the method signature.
+func generateSignature(method model.Method) string {
+	return "func" + generateParams(method.In) + " " + generateResults(method.Out)
+}
+
+// paramName returns the name a parameter is declared with in generated code.
+func paramName(param model.Parameter) string {
+	if param.Type == "context.Context" {
+		return "ctx"
+	}
+
+	return param.Name
+}
+
+// interfaceType returns the source interface type qualified with the source package alias.
+func interfaceType(pkg model.Package, ifce model.Interface) string {
+	return pkg.Name + "." + ifce.Name
+}
+
+// returnsError reports whether the last method result is an error.
+func returnsError(method model.Method) bool {
+	return len(method.Out) > 0 && method.Out[len(method.Out)-1].Type == "error"
+}
+
+// generateZeroReturn writes a return statement yielding zero values for all results.
+// When errExpr isn't empty, the trailing error result is set to it instead.
+func generateZeroReturn(g *protogen.GeneratedFile, results []model.Parameter, errExpr string) {
+	values := make([]string, 0, len(results))
+
+	if errExpr != "" {
+		results = results[:len(results)-1]
+	}
+
+	for _, result := range results {
+		g.P("var ", result.Name, " ", result.Type)
+		values = append(values, result.Name)
+	}
+
+	if errExpr != "" {
+		values = append(values, errExpr)
+	}
+
+	g.P("return ", strings.Join(values, ", "))
+}
+
+// contextArg returns the expression passing the method context,
+// context.Background() is used for methods without a context parameter.
+func contextArg(method model.Method, names map[string]string) string {
+	for _, param := range method.In {
+		if param.Type == "context.Context" {
+			return paramName(param)
+		}
+	}
+
+	return names["context"] + ".Background()"
+}
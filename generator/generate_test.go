@@ -0,0 +1,185 @@
+package generator
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+	sourceParser "github.com/not-for-prod/implgen/parser"
+	"github.com/not-for-prod/implgen/writer"
+)
+
+// exampleSrc is the source file of TestInterface the kinds are generated for
+const exampleSrc = "../example/in/interface.go"
+
+var (
+	exampleOnce    sync.Once
+	examplePackage model.Package
+	exampleErr     error
+
+	// sourceFset and sourceImporter are shared by type checks, so that imported packages are checked once
+	sourceFset     = token.NewFileSet()
+	sourceImporter = importer.ForCompiler(sourceFset, "source", nil).(types.ImporterFrom)
+)
+
+// parseExample returns the parsed package of exampleSrc, it is parsed once for all tests
+func parseExample(t *testing.T) model.Package {
+	t.Helper()
+
+	exampleOnce.Do(func() {
+		examplePackage, exampleErr = sourceParser.NewCommand(exampleSrc).Execute()
+	})
+	if exampleErr != nil {
+		t.Fatalf("failed to parse %s: %v", exampleSrc, exampleErr)
+	}
+
+	return examplePackage
+}
+
+// newExampleCommand returns the Command generating kinds of TestInterface into out
+func newExampleCommand(kinds ...string) *Command {
+	return NewCommand(
+		"out",
+		"TestInterface",
+		"Implementation",
+		"",
+		false,
+		kinds,
+		MultiSelectFirst,
+		GRPCServer{},
+		nil,
+		false,
+		ConstructorPositional,
+	)
+}
+
+// generateExample generates the example package with cmd, formats the files as the writer does
+// and type checks them as a single package. Stubs are sources of packages outside of the module
+// the generated code imports, keyed by their paths. Files are returned keyed by their base names
+func generateExample(t *testing.T, cmd *Command, pkg model.Package, stubs map[string]string) map[string]string {
+	t.Helper()
+
+	files, err := cmd.Execute(pkg)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	generated := make(map[string]string, len(files))
+	for i, file := range files {
+		files[i].Data, err = writer.Format(file.Path, file.Data)
+		if err != nil {
+			t.Fatalf("failed to format %s: %v\n%s", file.Path, err, file.Data)
+		}
+
+		generated[filepath.Base(file.Path)] = string(files[i].Data)
+	}
+
+	typeCheck(t, files, stubs)
+
+	return generated
+}
+
+// typeCheck type checks files as a single package importing stubs by their paths
+// and other packages from their sources
+func typeCheck(t *testing.T, files []model.File, stubs map[string]string) {
+	t.Helper()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	astFiles := make([]*ast.File, 0, len(files))
+	for _, file := range files {
+		astFile, err := parser.ParseFile(sourceFset, file.Path, file.Data, 0)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", file.Path, err)
+		}
+		astFiles = append(astFiles, astFile)
+	}
+
+	imp := &stubImporter{
+		fset:   sourceFset,
+		dir:    dir,
+		stubs:  stubs,
+		source: sourceImporter,
+		pkgs:   make(map[string]*types.Package),
+	}
+
+	var errs []string
+	conf := types.Config{
+		Importer: imp,
+		Error: func(err error) {
+			errs = append(errs, err.Error())
+		},
+	}
+	_, _ = conf.Check("github.com/not-for-prod/implgen/out", sourceFset, astFiles, nil)
+
+	if len(errs) > 0 {
+		t.Errorf("generated code doesn't compile:\n%s", strings.Join(errs, "\n"))
+	}
+}
+
+// stubImporter imports stub packages from their sources and others with source,
+// resolving them from dir within the module
+type stubImporter struct {
+	fset   *token.FileSet
+	dir    string
+	stubs  map[string]string
+	source types.ImporterFrom
+	pkgs   map[string]*types.Package
+}
+
+func (i *stubImporter) Import(path string) (*types.Package, error) {
+	return i.ImportFrom(path, i.dir, 0)
+}
+
+func (i *stubImporter) ImportFrom(path, _ string, mode types.ImportMode) (*types.Package, error) {
+	src, ok := i.stubs[path]
+	if !ok {
+		return i.source.ImportFrom(path, i.dir, mode)
+	}
+	if pkg, ok := i.pkgs[path]; ok {
+		return pkg, nil
+	}
+
+	file, err := parser.ParseFile(i.fset, path+"/stub.go", src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := (&types.Config{Importer: i}).Check(path, i.fset, []*ast.File{file}, nil)
+	if err != nil {
+		return nil, err
+	}
+	i.pkgs[path] = pkg
+
+	return pkg, nil
+}
+
+func TestExecuteStub(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindStub), parseExample(t), nil)
+
+	for _, name := range []string{"implementation.go", "a.go", "b.go", "c.go", "d.go", "e.go", "f.go"} {
+		if _, ok := generated[name]; !ok {
+			t.Errorf("Execute() didn't generate %s", name)
+		}
+	}
+}
+
+func TestExecuteAssertion(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindStub), parseExample(t), nil)
+
+	want := "var _ in.TestInterface = (*Implementation)(nil)"
+	if !strings.Contains(generated["implementation.go"], want) {
+		t.Errorf("implementation.go lacks %q:\n%s", want, generated["implementation.go"])
+	}
+}
@@ -0,0 +1,261 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// GRPCServer describes the protoc-generated service server interface
+// the KindGRPC adapter is generated against.
+type GRPCServer struct {
+	// Package is the parsed package containing the protoc-generated service.
+	Package model.Package
+
+	// Name is the name of the XxxServer interface. If empty, the only server interface
+	// of the package is used, see server.
+	Name string
+}
+
+// server finds the server interface in the parsed protoc-generated package. Without a name,
+// it is the only interface with the mustEmbedUnimplementedXxxServer method besides UnsafeXxxServer,
+// or the only one with the `Server` suffix besides stream interfaces for older generators.
+func (s GRPCServer) server() (model.Interface, error) {
+	if len(s.Package.Interfaces) == 0 {
+		return model.Interface{}, errors.New("grpc server package isn't set")
+	}
+
+	var found []model.Interface
+
+	for _, ifce := range s.Package.Interfaces {
+		if s.Name != "" && ifce.Name == s.Name || s.Name == "" && !unsafeServer(ifce) && embedsUnimplemented(ifce) {
+			found = append(found, ifce)
+		}
+	}
+
+	if s.Name == "" && len(found) == 0 {
+		for _, ifce := range s.Package.Interfaces {
+			if strings.HasSuffix(ifce.Name, "Server") && !unsafeServer(ifce) && !strings.Contains(ifce.Name, "_") {
+				found = append(found, ifce)
+			}
+		}
+	}
+
+	switch {
+	case len(found) == 1:
+		return found[0], nil
+	case s.Name != "":
+		return model.Interface{}, fmt.Errorf("grpc server interface %s not found", s.Name)
+	case len(found) == 0:
+		return model.Interface{}, errors.New("grpc server interface not found, set its name")
+	default:
+		return model.Interface{}, errors.New("grpc server interface is ambiguous, set its name")
+	}
+}
+
+// embedsUnimplemented reports whether the interface requires embedding UnimplementedXxxServer,
+// as server interfaces generated by protoc-gen-go-grpc do.
+func embedsUnimplemented(ifce model.Interface) bool {
+	for _, method := range ifce.Methods {
+		if strings.HasPrefix(method.Name, "mustEmbedUnimplemented") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unsafeServer reports whether the interface is UnsafeXxxServer opting out of forward compatibility.
+func unsafeServer(ifce model.Interface) bool {
+	return strings.HasPrefix(ifce.Name, "Unsafe")
+}
+
+// grpcName returns the name of the gRPC server adapter struct for the interface.
+func grpcName(ifce model.Interface) string {
+	return "GRPC" + ifce.Name
+}
+
+// generateGRPC generates the gRPC server adapter: a struct embedding the protoc-generated
+// UnimplementedXxxServer and holding the interface implementation, with an adapter skeleton
+// for every server method matching an interface method by name.
+func (cmd *Command) generateGRPC(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	server, err := cmd.grpcServer.server()
+	if err != nil {
+		return nil, err
+	}
+
+	grpcPkg := cmd.grpcServer.Package
+	imports := append(
+		[]model.Import{
+			{Path: "context"},
+			{Path: "google.golang.org/grpc/codes"},
+			{Path: "google.golang.org/grpc/status"},
+		},
+		grpcPkg.Imports...,
+	)
+
+	file, err := cmd.generateFile(
+		pkg, ifce, "grpc.go", imports, func(g *protogen.GeneratedFile, names map[string]string) {
+			name := grpcName(ifce)
+			recv := newScope(ifce.Methods, "ctx", "request", "stream").declare("s")
+
+			g.P("// ", name, " adapts ", interfaceType(pkg, ifce), " to ", grpcPkg.Name, ".", server.Name, ".")
+			g.P("type ", name, " struct {")
+			g.P(grpcPkg.Name, ".Unimplemented", server.Name)
+			g.P()
+			g.P("Impl ", interfaceType(pkg, ifce))
+			g.P("}")
+			g.P()
+
+			for _, grpcMethod := range server.Methods {
+				for _, method := range ifce.Methods {
+					if method.Name == grpcMethod.Name {
+						generateGRPCMethod(g, names, name, recv, grpcMethod, method)
+					}
+				}
+			}
+
+			g.P("// grpcError translates an error returned by the implementation into a gRPC status error.")
+			g.P("func grpcError(err error) error {")
+			g.P("if _, ok := ", names["google.golang.org/grpc/status"], ".FromError(err); ok {")
+			g.P("return err")
+			g.P("}")
+			g.P()
+			g.P("// TODO: translate domain errors into matching codes.")
+			g.P(
+				"return ", names["google.golang.org/grpc/status"], ".Error(",
+				names["google.golang.org/grpc/codes"], ".Internal, err.Error())",
+			)
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
+
+// generateGRPCMethod writes the adapter skeleton of a single gRPC server method:
+// request mapping stub, interface call, error translation and response mapping stub.
+func generateGRPCMethod(
+	g *protogen.GeneratedFile,
+	names map[string]string,
+	name, recv string,
+	grpcMethod, method model.Method,
+) {
+	// parameters of the interface method become locals, gRPC parameters are named around them
+	local := newScope([]model.Method{method}, recv)
+	hasContext := contextArg(method, names) == "ctx"
+
+	// built by hand, generateParams names every context ctx
+	grpcParams := make([]string, 0, len(grpcMethod.In))
+	ctx, stream := "", ""
+	for _, param := range grpcMethod.In {
+		switch {
+		case param.Type == "context.Context" && hasContext:
+			param.Name = "ctx"
+			ctx = param.Name
+		case param.Type == "context.Context":
+			param.Name = local.declare("ctx")
+			ctx = param.Name
+		case strings.HasSuffix(param.Type, "Server") || strings.HasPrefix(param.Type, "grpc."):
+			param.Name = local.declare("stream")
+			stream = param.Name
+		default:
+			param.Name = local.declare("request")
+		}
+		grpcParams = append(grpcParams, param.Name+" "+param.Type)
+	}
+	if ctx == "" && stream != "" {
+		ctx = stream + ".Context()"
+	}
+
+	g.P(
+		"func (", recv, " *", name, ") ", grpcMethod.Name, "(", strings.Join(grpcParams, ", "), ") ",
+		generateResults(grpcMethod.Out), " {",
+	)
+
+	declared := false
+	if ctx != "ctx" && hasContext {
+		if ctx == "" {
+			ctx = names["context"] + ".Background()"
+		}
+		g.P("ctx := ", ctx)
+		declared = true
+	}
+	for _, param := range method.In {
+		if param.Type != "context.Context" {
+			g.P("var ", param.Name, " ", strings.Replace(param.Type, "...", "[]", 1), " // TODO: map from the request")
+			declared = true
+		}
+	}
+	if declared {
+		g.P()
+	}
+
+	values := make([]string, 0, len(method.Out))
+	for _, result := range method.Out {
+		values = append(values, result.Name)
+	}
+	err := ""
+	if returnsError(method) {
+		err = local.declare("err")
+		values[len(values)-1] = err
+	}
+	translate := returnsError(method) && returnsError(grpcMethod)
+
+	call := recv + ".Impl." + method.Name + generateArgs(method.In)
+	if len(values) > 0 {
+		call = strings.Join(values, ", ") + " := " + call
+	}
+	g.P(call)
+
+	if translate {
+		zero := make([]string, 0, len(grpcMethod.Out))
+		for _, result := range grpcMethod.Out[:len(grpcMethod.Out)-1] {
+			zero = append(zero, grpcZero(result.Type))
+		}
+
+		g.P("if ", err, " != nil {")
+		g.P("return ", strings.Join(append(zero, "grpcError("+err+")"), ", "))
+		g.P("}")
+		values = values[:len(values)-1]
+	}
+	g.P()
+
+	if len(values) > 0 {
+		g.P("// TODO: map to the response")
+		for _, value := range values {
+			g.P("_ = ", value)
+		}
+		g.P()
+	}
+
+	results := make([]string, 0, len(grpcMethod.Out))
+	for _, result := range grpcMethod.Out {
+		if strings.HasPrefix(result.Type, "*") {
+			results = append(results, "&"+strings.TrimPrefix(result.Type, "*")+"{}")
+		} else {
+			results = append(results, grpcZero(result.Type))
+		}
+	}
+
+	if len(results) > 0 {
+		g.P("return ", strings.Join(results, ", "))
+	}
+	g.P("}")
+	g.P()
+}
+
+// grpcZero returns the zero value expression of a gRPC method result type.
+func grpcZero(typ string) string {
+	if typ == "error" || strings.HasPrefix(typ, "*") {
+		return "nil"
+	}
+
+	return typ + "{}"
+}
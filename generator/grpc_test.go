@@ -0,0 +1,234 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+)
+
+var (
+	// greeterServer is the parsed protoc-gen-go-grpc output of greeter.proto with a unary
+	// and a server-streaming method
+	greeterServer = model.Interface{
+		Name: "GreeterServer",
+		Methods: []model.Method{
+			{
+				Name: "SayHello",
+				In: []model.Parameter{
+					{Name: "arg0", Type: "context.Context", Kind: model.KindContext},
+					{Name: "arg1", Type: "*greeter.HelloRequest"},
+				},
+				Out: []model.Parameter{{Name: "result0", Type: "*greeter.HelloReply"}, {Name: "result1", Type: "error"}},
+			},
+			{
+				Name: "SayHellos",
+				In: []model.Parameter{
+					{Name: "arg0", Type: "*greeter.HelloRequest"},
+					{Name: "arg1", Type: "grpc.ServerStreamingServer[greeter.HelloReply]"},
+				},
+				Out: []model.Parameter{{Name: "result0", Type: "error"}},
+			},
+			{Name: "mustEmbedUnimplementedGreeterServer"},
+		},
+	}
+	greeterClient = model.Interface{
+		Name: "GreeterClient",
+		Methods: []model.Method{{
+			Name: "SayHello",
+			In: []model.Parameter{
+				{Name: "ctx", Type: "context.Context", Kind: model.KindContext},
+				{Name: "in", Type: "*greeter.HelloRequest"},
+				{Name: "opts", Type: "...grpc.CallOption"},
+			},
+			Out: []model.Parameter{{Name: "result0", Type: "*greeter.HelloReply"}, {Name: "result1", Type: "error"}},
+		}},
+	}
+	unsafeGreeterServer = model.Interface{
+		Name:    "UnsafeGreeterServer",
+		Methods: []model.Method{{Name: "mustEmbedUnimplementedGreeterServer"}},
+	}
+	greeterImports = []model.Import{
+		{Alias: "context", Path: "context"},
+		{Alias: "grpc", Path: "google.golang.org/grpc"},
+		{Alias: "greeter", Path: "example.com/greeter"},
+	}
+	greeterStubs = map[string]string{
+		"google.golang.org/grpc": `package grpc
+
+import "context"
+
+type ServerStreamingServer[Res any] interface {
+	Send(*Res) error
+	Context() context.Context
+}
+`,
+		"google.golang.org/grpc/codes": "package codes\n\ntype Code uint32\n\nconst Internal Code = 13\n",
+		"google.golang.org/grpc/status": `package status
+
+import "google.golang.org/grpc/codes"
+
+type Status struct{}
+
+func FromError(err error) (*Status, bool) { return nil, false }
+
+func Error(c codes.Code, msg string) error { return nil }
+`,
+		"example.com/greeter": `package greeter
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+type HelloRequest struct{ Name string }
+
+type HelloReply struct{ Message string }
+
+type GreeterServer interface {
+	SayHello(context.Context, *HelloRequest) (*HelloReply, error)
+	SayHellos(*HelloRequest, grpc.ServerStreamingServer[HelloReply]) error
+	mustEmbedUnimplementedGreeterServer()
+}
+
+type UnimplementedGreeterServer struct{}
+
+func (UnimplementedGreeterServer) SayHello(context.Context, *HelloRequest) (*HelloReply, error) {
+	return nil, nil
+}
+
+func (UnimplementedGreeterServer) SayHellos(*HelloRequest, grpc.ServerStreamingServer[HelloReply]) error {
+	return nil
+}
+
+func (UnimplementedGreeterServer) mustEmbedUnimplementedGreeterServer() {}
+
+type Service interface {
+	SayHello(ctx context.Context, name string) (string, error)
+	SayHellos(request string, stream int) ([]string, error)
+}
+`,
+	}
+)
+
+func TestGRPCServerServer(t *testing.T) {
+	// older generators declare stream interfaces and no mustEmbedUnimplementedXxxServer methods
+	legacyServer := model.Interface{Name: "GreeterServer", Methods: greeterServer.Methods[:2]}
+	legacyStream := model.Interface{Name: "Greeter_SayHellosServer"}
+
+	tests := []struct {
+		name       string
+		interfaces []model.Interface
+		serverName string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "embedding UnimplementedXxxServer",
+			interfaces: []model.Interface{greeterClient, greeterServer, unsafeGreeterServer},
+			want:       "GreeterServer",
+		},
+		{
+			name:       "older generator",
+			interfaces: []model.Interface{greeterClient, legacyServer, legacyStream},
+			want:       "GreeterServer",
+		},
+		{
+			name:       "by name",
+			interfaces: []model.Interface{greeterClient, greeterServer, unsafeGreeterServer},
+			serverName: "GreeterClient",
+			want:       "GreeterClient",
+		},
+		{
+			name:       "missing name",
+			interfaces: []model.Interface{greeterServer},
+			serverName: "EchoServer",
+			wantErr:    true,
+		},
+		{
+			name: "ambiguous",
+			interfaces: []model.Interface{
+				greeterServer,
+				{Name: "EchoServer", Methods: []model.Method{{Name: "mustEmbedUnimplementedEchoServer"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "no package",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := GRPCServer{Package: model.Package{Name: "greeter", Interfaces: tt.interfaces}, Name: tt.serverName}
+
+			got, err := server.server()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("server() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Name != tt.want {
+				t.Errorf("server() = %s, want %s", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateGRPC(t *testing.T) {
+	pkg := model.Package{
+		Name:    "greeter",
+		Imports: greeterImports,
+		Interfaces: []model.Interface{{
+			Name: "Service",
+			Methods: []model.Method{
+				{
+					Name: "SayHello",
+					In: []model.Parameter{
+						{Name: "ctx", Type: "context.Context", Kind: model.KindContext},
+						{Name: "name", Type: "string"},
+					},
+					Out: []model.Parameter{{Name: "result0", Type: "string"}, {Name: "result1", Type: "error"}},
+				},
+				{
+					// parameters are named like the gRPC ones
+					Name: "SayHellos",
+					In: []model.Parameter{
+						{Name: "request", Type: "string"},
+						{Name: "stream", Type: "int"},
+					},
+					Out: []model.Parameter{{Name: "result0", Type: "[]string"}, {Name: "result1", Type: "error"}},
+				},
+			},
+		}},
+	}
+
+	cmd := newExampleCommand(KindGRPC)
+	cmd.interfaceName = "Service"
+	cmd.grpcServer = GRPCServer{
+		Package: model.Package{
+			Name:       "greeter",
+			Imports:    greeterImports,
+			Interfaces: []model.Interface{greeterClient, greeterServer, unsafeGreeterServer},
+		},
+	}
+
+	generated := generateExample(t, cmd, pkg, greeterStubs)
+
+	grpc, ok := generated["grpc.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate grpc.go")
+	}
+
+	for _, want := range []string{
+		"greeter.UnimplementedGreeterServer",
+		"func (s *GRPCService) SayHello(ctx context.Context, request *greeter.HelloRequest) (*greeter.HelloReply, error) {",
+		"result0, err := s.Impl.SayHello(ctx, name)",
+		"stream1 grpc.ServerStreamingServer[greeter.HelloReply]) error {",
+		"return status.Error(codes.Internal, err.Error())",
+	} {
+		if !strings.Contains(grpc, want) {
+			t.Errorf("grpc.go lacks %q:\n%s", want, grpc)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"github.com/not-for-prod/implgen/model"
+	stringCase "github.com/not-for-prod/implgen/pkg/string-case"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// httpRoute returns the path the method is served on.
+func httpRoute(method model.Method) string {
+	return "/" + stringCase.SnakeCase(method.Name)
+}
+
+// httpHandlerName returns the name of the function creating the method http.Handler.
+func httpHandlerName(method model.Method) string {
+	return method.Name + "Handler"
+}
+
+// generateHTTP generates the net/http transport of the interface: an http.Handler per method
+// decoding the JSON request struct, calling the implementation and encoding the JSON response
+// struct or the Error envelope, and a ServeMux registering them as `POST /<snake_case_method>`.
+func (cmd *Command) generateHTTP(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{{Path: "encoding/json"}, {Path: "errors"}, {Path: "io"}, {Path: "net/http"}}
+
+	file, err := cmd.generateFile(
+		pkg, ifce, "http.go", imports, func(g *protogen.GeneratedFile, names map[string]string) {
+			typ := interfaceType(pkg, ifce)
+			http, json := names["net/http"], names["encoding/json"]
+
+			g.P("// NewHTTP", ifce.Name, " returns an http.Handler serving ", typ, " methods")
+			g.P("// as `POST /<snake_case_method>` JSON endpoints.")
+			g.P("func NewHTTP", ifce.Name, "(impl ", typ, ") ", http, ".Handler {")
+			g.P("mux := ", http, ".NewServeMux()")
+			for _, method := range ifce.Methods {
+				g.P("mux.Handle(\"POST ", httpRoute(method), "\", ", httpHandlerName(method), "(impl))")
+			}
+			g.P()
+			g.P("return mux")
+			g.P("}")
+			g.P()
+
+			for _, method := range ifce.Methods {
+				g.P("// ", httpHandlerName(method), " returns an http.Handler serving ", typ, ".", method.Name, ".")
+				g.P("func ", httpHandlerName(method), "(impl ", typ, ") ", http, ".Handler {")
+				g.P("return ", http, ".HandlerFunc(func(w ", http, ".ResponseWriter, r *", http, ".Request) {")
+				g.P("var req ", requestName(method))
+				g.P(
+					"if err := ", json, ".NewDecoder(r.Body).Decode(&req); err != nil && !",
+					names["errors"], ".Is(err, ", names["io"], ".EOF) {",
+				)
+				g.P("writeError(w, ", http, ".StatusBadRequest, err)")
+				g.P("return")
+				g.P("}")
+				g.P()
+				g.P("var res ", responseName(method))
+				generateCall(g, method, "impl", "req", "r.Context()", "res")
+				if returnsError(method) {
+					g.P("if err != nil {")
+					g.P("writeError(w, ", http, ".StatusInternalServerError, err)")
+					g.P("return")
+					g.P("}")
+				}
+				g.P()
+				g.P("writeJSON(w, ", http, ".StatusOK, res)")
+				g.P("})")
+				g.P("}")
+				g.P()
+			}
+
+			g.P("// writeError writes err wrapped into the Error envelope.")
+			g.P("func writeError(w ", http, ".ResponseWriter, code int, err error) {")
+			g.P("writeJSON(w, code, &Error{Message: err.Error()})")
+			g.P("}")
+			g.P()
+			g.P("// writeJSON writes v encoded as JSON.")
+			g.P("func writeJSON(w ", http, ".ResponseWriter, code int, v any) {")
+			g.P("w.Header().Set(\"Content-Type\", \"application/json\")")
+			g.P("w.WriteHeader(code)")
+			g.P("_ = ", json, ".NewEncoder(w).Encode(v)")
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
+
+// generateCall writes the call of the method on impl taking its parameters from
+// the request struct req and storing results into the response struct res
+// and the error into err variable.
+func generateCall(g *protogen.GeneratedFile, method model.Method, impl, req, ctx, res string) {
+	call := impl + "." + method.Name + generateCallArgs(method, req, ctx)
+
+	switch {
+	case len(method.Out) == 0:
+		g.P(call)
+	case len(responseResults(method)) == 0:
+		g.P("err := ", call)
+	default:
+		if returnsError(method) {
+			g.P("var err error")
+		}
+		g.P(generateCallResults(method, res), " = ", call)
+	}
+}
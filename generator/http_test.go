@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateHTTP(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindHTTP), parseExample(t), nil)
+
+	http, ok := generated["http.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate http.go")
+	}
+	messages, ok := generated["messages.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate messages.go the handlers rely on")
+	}
+
+	for _, want := range []string{
+		"func NewHTTPTestInterface(impl in.TestInterface) http.Handler {",
+		`mux.Handle("POST /e", EHandler(impl))`,
+		"res.Result0, err = impl.E(r.Context(), req.Req)",
+	} {
+		if !strings.Contains(http, want) {
+			t.Errorf("http.go lacks %q:\n%s", want, http)
+		}
+	}
+
+	for _, want := range []string{
+		"type DRequest struct {",
+		"Opts []dto.GoRequest `json:\"opts\"`",
+		"Result0 in.EResponse `json:\"result0\"`",
+		"type Error struct {",
+	} {
+		if !strings.Contains(messages, want) {
+			t.Errorf("messages.go lacks %q:\n%s", want, messages)
+		}
+	}
+}
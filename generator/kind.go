@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+const (
+	// KindStub generates a struct with `panic("implement me")` method stubs.
+	KindStub = "stub"
+	// KindFuncs generates an adapter struct with one func field per method.
+	KindFuncs = "funcs"
+	// KindMiddleware generates a Middleware type and a Chain helper, implies KindFuncs.
+	KindMiddleware = "middleware"
+	// KindMulti generates a fan-out implementation broadcasting calls to several delegates.
+	KindMulti = "multi"
+	// KindFallback generates a failover implementation calling a secondary delegate on errors.
+	KindFallback = "fallback"
+	// KindSwitch generates a feature-flag switching implementation routing calls to new or old delegate.
+	KindSwitch = "switch"
+	// KindGRPC generates a gRPC server adapter for a protoc-generated service, see GRPCServer.
+	KindGRPC = "grpc"
+	// KindMessages generates request and response structs of every method shared by transports.
+	KindMessages = "messages"
+	// KindHTTP generates net/http JSON handlers, implies KindMessages.
+	KindHTTP = "http"
+	// KindClient generates an HTTP/JSON client implementing the interface via KindHTTP handlers.
+	KindClient = "client"
+	// KindRPC generates a net/rpc receiver and client pair, implies KindMessages.
+	KindRPC = "rpc"
+	// KindCLI generates a cobra command tree exposing methods as subcommands, implies KindMessages.
+	KindCLI = "cli"
+	// KindTest generates table-driven test skeletons for KindStub implementation methods.
+	KindTest = "test"
+	// KindContract generates a reusable contract test suite run against any implementation.
+	KindContract = "contract"
+	// KindBench generates benchmark skeletons for KindStub implementation methods.
+	KindBench = "bench"
+	// KindFuzz generates fuzz test skeletons for KindStub implementation methods taking primitives.
+	KindFuzz = "fuzz"
+	// KindFX generates an fx module providing the KindStub implementation as the interface.
+	KindFX = "fx"
+	// KindWire generates a wire provider set binding the KindStub implementation to the interface.
+	KindWire = "wire"
+)
+
+// kindGenerator generates all files of a single kind for the given interface.
+type kindGenerator func(cmd *Command, pkg model.Package, ifce model.Interface) ([]model.File, error)
+
+// kindGenerators maps kind names accepted by Command to their generators.
+var kindGenerators = map[string]kindGenerator{
+	KindStub:       (*Command).generateInterface,
+	KindFuncs:      (*Command).generateFuncs,
+	KindMiddleware: (*Command).generateMiddleware,
+	KindMulti:      (*Command).generateMulti,
+	KindFallback:   (*Command).generateFallback,
+	KindSwitch:     (*Command).generateSwitch,
+	KindGRPC:       (*Command).generateGRPC,
+	KindMessages:   (*Command).generateMessages,
+	KindHTTP:       (*Command).generateHTTP,
+	KindClient:     (*Command).generateClient,
+	KindRPC:        (*Command).generateRPC,
+	KindCLI:        (*Command).generateCLI,
+	KindTest:       (*Command).generateTests,
+	KindContract:   (*Command).generateContract,
+	KindBench:      (*Command).generateBenchmarks,
+	KindFuzz:       (*Command).generateFuzzTests,
+	KindFX:         (*Command).generateFX,
+	KindWire:       (*Command).generateWire,
+}
+
+// kindDependencies lists kinds whose generated code relies on the output of other kinds.
+var kindDependencies = map[string][]string{
+	KindMiddleware: {KindFuncs},
+	KindHTTP:       {KindMessages},
+	KindClient:     {KindMessages},
+	KindRPC:        {KindMessages},
+	KindCLI:        {KindMessages},
+}
+
+// kindConflicts lists kinds generating the same files, they can't be generated together.
+var kindConflicts = map[string]string{
+	KindFX:   KindWire,
+	KindWire: KindFX,
+}
+
+// resolveKinds validates kinds and appends their dependencies,
+// dropping duplicates while keeping the order of first occurrence.
+func resolveKinds(kinds []string) ([]string, error) {
+	resolved := make([]string, 0, len(kinds))
+	seen := make(map[string]bool, len(kinds))
+
+	var add func(kinds []string) error
+	add = func(kinds []string) error {
+		for _, kind := range kinds {
+			if _, ok := kindGenerators[kind]; !ok {
+				return fmt.Errorf("unknown kind %q", kind)
+			}
+			if seen[kind] {
+				continue
+			}
+
+			seen[kind] = true
+			resolved = append(resolved, kind)
+
+			if err := add(kindDependencies[kind]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := add(kinds); err != nil {
+		return nil, err
+	}
+
+	for _, kind := range resolved {
+		if conflict, ok := kindConflicts[kind]; ok && seen[conflict] {
+			return nil, fmt.Errorf("kinds %q and %q can't be generated together", kind, conflict)
+		}
+	}
+
+	return resolved, nil
+}
+
+// generateFile generates a standalone Go file named name in the interface package,
+// writing the header with the extra imports and delegating the rest of the content to body,
+// which refers to the imported packages by names, see importNames.
+func (cmd *Command) generateFile(
+	pkg model.Package,
+	ifce model.Interface,
+	name string,
+	imports []model.Import,
+	body func(g *protogen.GeneratedFile, names map[string]string),
+) (model.File, error) {
+	p := protogen.Plugin{}
+	g := p.NewGeneratedFile("", "")
+
+	cmd.generateHeader(g, pkg, ifce, imports...)
+	body(g, importNames(pkg, imports...))
+
+	content, err := g.Content()
+	if err != nil {
+		return model.File{}, fmt.Errorf("failed to generate %s for %s, err: %w", name, ifce.Name, err)
+	}
+
+	return model.File{
+		Path: filepath.Join(cmd.dstPath(ifce), name),
+		Data: content,
+	}, nil
+}
@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResolveKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		kinds   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "no dependencies",
+			kinds: []string{KindStub, KindMulti},
+			want:  []string{KindStub, KindMulti},
+		},
+		{
+			name:  "dependencies follow their kinds",
+			kinds: []string{KindMiddleware, KindStub},
+			want:  []string{KindMiddleware, KindFuncs, KindStub},
+		},
+		{
+			name:  "shared dependencies once",
+			kinds: []string{KindHTTP, KindClient, KindRPC},
+			want:  []string{KindHTTP, KindMessages, KindClient, KindRPC},
+		},
+		{
+			name:  "duplicates dropped",
+			kinds: []string{KindFuncs, KindMiddleware, KindFuncs},
+			want:  []string{KindFuncs, KindMiddleware},
+		},
+		{
+			name:    "unknown kind",
+			kinds:   []string{KindStub, "mock"},
+			wantErr: true,
+		},
+		{
+			name:    "conflicting kinds",
+			kinds:   []string{KindFX, KindStub, KindWire},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveKinds(tt.kinds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveKinds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("resolveKinds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
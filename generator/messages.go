@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	stringCase "github.com/not-for-prod/implgen/pkg/string-case"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// requestName returns the name of the struct holding the method parameters.
+func requestName(method model.Method) string {
+	return method.Name + "Request"
+}
+
+// responseName returns the name of the struct holding the method results.
+func responseName(method model.Method) string {
+	return method.Name + "Response"
+}
+
+// requestParams returns the method parameters carried by the request struct,
+// the context is passed by transports on their own.
+func requestParams(method model.Method) []model.Parameter {
+	params := make([]model.Parameter, 0, len(method.In))
+
+	for _, param := range method.In {
+		if param.Type != "context.Context" {
+			params = append(params, param)
+		}
+	}
+
+	return params
+}
+
+// responseResults returns the method results carried by the response struct,
+// the trailing error is passed via the Error envelope.
+func responseResults(method model.Method) []model.Parameter {
+	if returnsError(method) {
+		return method.Out[:len(method.Out)-1]
+	}
+
+	return method.Out
+}
+
+// fieldName returns the request or response struct field name of the parameter.
+func fieldName(param model.Parameter) string {
+	return stringCase.PascalCase(param.Name)
+}
+
+// fieldType returns the request or response struct field type of the parameter.
+func fieldType(param model.Parameter) string {
+	return strings.Replace(param.Type, "...", "[]", 1)
+}
+
+// generateMessages generates the transport messages shared by KindHTTP, KindClient and KindRPC:
+// request and response structs for every method and the Error envelope.
+func (cmd *Command) generateMessages(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	file, err := cmd.generateFile(
+		pkg, ifce, "messages.go", nil, func(g *protogen.GeneratedFile, _ map[string]string) {
+			typ := interfaceType(pkg, ifce)
+
+			for _, method := range ifce.Methods {
+				g.P("// ", requestName(method), " holds the parameters of ", typ, ".", method.Name, ".")
+				g.P("type ", requestName(method), " struct {")
+				for _, param := range requestParams(method) {
+					g.P(fieldName(param), " ", fieldType(param), " `json:\"", stringCase.SnakeCase(param.Name), "\"`")
+				}
+				g.P("}")
+				g.P()
+				g.P("// ", responseName(method), " holds the results of ", typ, ".", method.Name, ".")
+				g.P("type ", responseName(method), " struct {")
+				for _, result := range responseResults(method) {
+					g.P(fieldName(result), " ", fieldType(result), " `json:\"", stringCase.SnakeCase(result.Name), "\"`")
+				}
+				g.P("}")
+				g.P()
+			}
+
+			g.P("// Error is the envelope of an error returned by ", typ, " methods.")
+			g.P("type Error struct {")
+			g.P("Message string `json:\"message\"`")
+			g.P("}")
+			g.P()
+			g.P("func (e *Error) Error() string {")
+			g.P("return e.Message")
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
+
+// generateCallArgs builds the call argument list of a method taking its parameters from
+// the request struct expression req and the context from ctx.
+func generateCallArgs(method model.Method, req, ctx string) string {
+	args := make([]string, 0, len(method.In))
+
+	for _, param := range method.In {
+		switch {
+		case param.Type == "context.Context":
+			args = append(args, ctx)
+		case strings.HasPrefix(param.Type, "..."):
+			args = append(args, req+"."+fieldName(param)+"...")
+		default:
+			args = append(args, req+"."+fieldName(param))
+		}
+	}
+
+	return "(" + strings.Join(args, ", ") + ")"
+}
+
+// generateCallResults builds the assignment targets of a method call storing results
+// into the response struct expression res and the error into err.
+func generateCallResults(method model.Method, res string) string {
+	targets := make([]string, 0, len(method.Out))
+
+	for _, result := range responseResults(method) {
+		targets = append(targets, res+"."+fieldName(result))
+	}
+	if returnsError(method) {
+		targets = append(targets, "err")
+	}
+
+	return strings.Join(targets, ", ")
+}
@@ -0,0 +1,34 @@
+package generator
+
+import (
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// generateMiddleware generates the composable decorator helpers for the interface:
+// a Middleware type and a Chain function applying middlewares to a base implementation.
+// Middlewares are meant to be written inline with the Funcs adapter, see KindFuncs.
+func (cmd *Command) generateMiddleware(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	file, err := cmd.generateFile(
+		pkg, ifce, "middleware.go", nil, func(g *protogen.GeneratedFile, _ map[string]string) {
+			typ := interfaceType(pkg, ifce)
+
+			g.P("// Middleware decorates ", typ, " with additional behaviour.")
+			g.P("type Middleware func(", typ, ") ", typ)
+			g.P()
+			g.P("// Chain wraps base with mws, the first middleware being the outermost one.")
+			g.P("func Chain(base ", typ, ", mws ...Middleware) ", typ, " {")
+			g.P("for i := len(mws) - 1; i >= 0; i-- {")
+			g.P("base = mws[i](base)")
+			g.P("}")
+			g.P()
+			g.P("return base")
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+)
+
+func TestGenerateMiddleware(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindStub, KindMiddleware), parseExample(t), nil)
+
+	middleware, ok := generated["middleware.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate middleware.go")
+	}
+	if _, ok = generated["funcs.go"]; !ok {
+		t.Error("Execute() didn't generate funcs.go the middlewares are written with")
+	}
+
+	for _, want := range []string{
+		"type Middleware func(in.TestInterface) in.TestInterface",
+		"func Chain(base in.TestInterface, mws ...Middleware) in.TestInterface",
+	} {
+		if !strings.Contains(middleware, want) {
+			t.Errorf("middleware.go lacks %q:\n%s", want, middleware)
+		}
+	}
+}
+
+// chainTest records the order middlewares applied by Chain are called in
+const chainTest = `package greeter
+
+import (
+	"strings"
+	"testing"
+
+	"example.com/greet"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next greet.Greeter) greet.Greeter {
+			return GreeterFuncs{GreetFunc: func(who string) string {
+				calls = append(calls, name)
+				return next.Greet(who)
+			}}
+		}
+	}
+	base := GreeterFuncs{GreetFunc: func(who string) string {
+		calls = append(calls, "base")
+		return who
+	}}
+
+	Chain(base, record("outer"), record("inner")).Greet("gopher")
+
+	if got := strings.Join(calls, ","); got != "outer,inner,base" {
+		t.Errorf("calls = %s, want outer,inner,base", got)
+	}
+}
+`
+
+func TestMiddlewareChainOrder(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	pkg := model.Package{
+		Name:    "greet",
+		Imports: []model.Import{{Alias: "greet", Path: "example.com/greet"}},
+		Interfaces: []model.Interface{{
+			Name: "Greeter",
+			Methods: []model.Method{{
+				Name: "Greet",
+				In:   []model.Parameter{{Name: "name", Type: "string", Kind: model.KindString, Basic: "string"}},
+				Out:  []model.Parameter{{Name: "result0", Type: "string"}},
+			}},
+		}},
+	}
+
+	greetSource := "package greet\n\ntype Greeter interface {\n\tGreet(name string) string\n}\n"
+
+	cmd := newExampleCommand(KindMiddleware)
+	cmd.interfaceName = "Greeter"
+
+	generated := generateExample(t, cmd, pkg, map[string]string{"example.com/greet": greetSource})
+
+	// the generated package is run in a module along with the source package and chainTest
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":   "module example.com/greet\n\ngo 1.22\n",
+		"greet.go": greetSource,
+		filepath.Join("out", "greeter", "chain_test.go"): chainTest,
+	}
+	for name, content := range generated {
+		files[filepath.Join("out", "greeter", name)] = content
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	test := exec.Command(goBin, "test", "./out/...")
+	test.Dir = dir
+	test.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	if out, err := test.CombinedOutput(); err != nil {
+		t.Errorf("go test of the generated middleware failed: %v\n%s", err, out)
+	}
+}
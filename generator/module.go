@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// generateFX generates the fx module providing the KindStub implementation
+// constructor annotated as the source interface.
+func (cmd *Command) generateFX(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{{Path: "go.uber.org/fx"}}
+
+	file, err := cmd.generateFile(
+		pkg, ifce, "module.go", imports, func(g *protogen.GeneratedFile, names map[string]string) {
+			typ := interfaceType(pkg, ifce)
+			fx := names["go.uber.org/fx"]
+
+			g.P("// Module provides ", cmd.implementationName, " as ", typ, ".")
+			g.P("var Module = ", fx, ".Module(")
+			g.P("\"", cmd.packageName(ifce), "\",")
+			g.P(fx, ".Provide(")
+			g.P(fx, ".Annotate(")
+			g.P("New", cmd.implementationName, ",")
+			g.P(fx, ".As(new(", typ, ")),")
+			g.P("),")
+			g.P("),")
+			g.P(")")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
+
+// generateWire generates the wire provider set of the KindStub implementation
+// constructor binding it to the source interface.
+func (cmd *Command) generateWire(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{{Path: "github.com/google/wire"}}
+
+	file, err := cmd.generateFile(
+		pkg, ifce, "module.go", imports, func(g *protogen.GeneratedFile, names map[string]string) {
+			typ := interfaceType(pkg, ifce)
+			wire := names["github.com/google/wire"]
+
+			g.P("// ProviderSet provides *", cmd.implementationName, " bound to ", typ, ".")
+			g.P("var ProviderSet = ", wire, ".NewSet(")
+			g.P("New", cmd.implementationName, ",")
+			g.P(wire, ".Bind(new(", typ, "), new(*", cmd.implementationName, ")),")
+			g.P(")")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+// moduleStubs are sources of the fx and wire APIs the provider modules use
+var moduleStubs = map[string]string{
+	"go.uber.org/fx": `package fx
+
+type Option interface{}
+
+type Annotation interface{}
+
+func Module(name string, opts ...Option) Option { return nil }
+
+func Provide(constructors ...any) Option { return nil }
+
+func Annotate(t any, anns ...Annotation) any { return nil }
+
+func As(interfaces ...any) Annotation { return nil }
+`,
+	"github.com/google/wire": `package wire
+
+type ProviderSet struct{}
+
+type Binding struct{}
+
+func NewSet(providers ...any) ProviderSet { return ProviderSet{} }
+
+func Bind(iface, to any) Binding { return Binding{} }
+`,
+}
+
+func TestGenerateModules(t *testing.T) {
+	tests := []struct {
+		kind string
+		want []string
+	}{
+		{
+			kind: KindFX,
+			want: []string{
+				`var Module = fx.Module(`,
+				`"test_interface",`,
+				"NewImplementation,",
+				"fx.As(new(in.TestInterface)),",
+			},
+		},
+		{
+			kind: KindWire,
+			want: []string{
+				"var ProviderSet = wire.NewSet(",
+				"NewImplementation,",
+				"wire.Bind(new(in.TestInterface), new(*Implementation)),",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			generated := generateExample(t, newExampleCommand(KindStub, tt.kind), parseExample(t), moduleStubs)
+
+			module, ok := generated["module.go"]
+			if !ok {
+				t.Fatal("Execute() didn't generate module.go")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(module, want) {
+					t.Errorf("module.go lacks %q:\n%s", want, module)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+const (
+	// MultiSelectFirst makes Multi methods return the results of the first delegate.
+	MultiSelectFirst = "first"
+	// MultiSelectLast makes Multi methods return the results of the last delegate.
+	MultiSelectLast = "last"
+)
+
+// multiName returns the name of the fan-out struct for the interface.
+func multiName(ifce model.Interface) string {
+	return "Multi" + ifce.Name
+}
+
+// generateMulti generates the fan-out implementation: a struct holding several delegates
+// which invokes every method on each of them, sequentially or concurrently, and joins errors.
+// Concurrent calls share a context canceled on the first error, like errgroup.WithContext,
+// yet every delegate is waited for and all errors are joined.
+// Methods with non-error results are rejected unless a selection policy is configured.
+func (cmd *Command) generateMulti(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	if cmd.multiSelect != "" && cmd.multiSelect != MultiSelectFirst && cmd.multiSelect != MultiSelectLast {
+		return nil, fmt.Errorf("unknown multi selection policy %q", cmd.multiSelect)
+	}
+
+	for _, method := range ifce.Methods {
+		if hasValues(method) && cmd.multiSelect == "" {
+			return nil, fmt.Errorf("method %s returns non-error results, multi selection policy is required", method.Name)
+		}
+	}
+
+	imports := []model.Import{{Path: "context"}, {Path: "errors"}, {Path: "sync"}}
+
+	file, err := cmd.generateFile(
+		pkg, ifce, "multi.go", imports, func(g *protogen.GeneratedFile, names map[string]string) {
+			name := multiName(ifce)
+			typ := interfaceType(pkg, ifce)
+			recv := newScope(ifce.Methods).declare("m")
+			ctxType := names["context"] + ".Context"
+
+			g.P("// ", name, " broadcasts every call to all delegates and joins their errors.")
+			if cmd.multiSelect != "" {
+				g.P("// Methods with results return the ones of the ", cmd.multiSelect, " delegate.")
+			}
+			g.P("type ", name, " struct {")
+			g.P("Delegates []", typ)
+			g.P()
+			g.P("// Concurrent makes methods call delegates concurrently instead of one by one,")
+			g.P("// the context passed to them is canceled once any of them fails.")
+			g.P("Concurrent bool")
+			g.P("}")
+			g.P()
+
+			for _, method := range ifce.Methods {
+				cmd.generateMultiMethod(g, names, name, recv, typ, method)
+			}
+
+			g.P("// call invokes fn for every delegate and waits for all of them to finish.")
+			g.P("// Concurrent calls share a context canceled once any of them returns an error,")
+			g.P("// like errgroup.WithContext, but the rest of them still run to completion.")
+			g.P("func (", recv, " ", name, ") call(ctx ", ctxType, ", fn func(ctx ", ctxType, ", i int, delegate ", typ, ") error) {")
+			g.P("if !", recv, ".Concurrent {")
+			g.P("for i, delegate := range ", recv, ".Delegates {")
+			g.P("_ = fn(ctx, i, delegate)")
+			g.P("}")
+			g.P()
+			g.P("return")
+			g.P("}")
+			g.P()
+			g.P("ctx, cancel := ", names["context"], ".WithCancel(ctx)")
+			g.P("defer cancel()")
+			g.P()
+			g.P("var wg ", names["sync"], ".WaitGroup")
+			g.P("for i, delegate := range ", recv, ".Delegates {")
+			g.P("wg.Add(1)")
+			g.P("go func(i int, delegate ", typ, ") {")
+			g.P("defer wg.Done()")
+			g.P()
+			g.P("if err := fn(ctx, i, delegate); err != nil {")
+			g.P("cancel()")
+			g.P("}")
+			g.P("}(i, delegate)")
+			g.P("}")
+			g.P("wg.Wait()")
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
+
+// generateMultiMethod writes a Multi method collecting the results of every delegate,
+// its locals are declared in the method scope so they don't shadow the parameters.
+func (cmd *Command) generateMultiMethod(
+	g *protogen.GeneratedFile,
+	names map[string]string,
+	name, recv, typ string,
+	method model.Method,
+) {
+	values := method.Out
+	if returnsError(method) {
+		values = method.Out[:len(method.Out)-1]
+	}
+
+	local := newScope([]model.Method{method}, recv)
+	delegates := recv + ".Delegates"
+
+	g.P("func (", recv, " ", name, ") ", method.Name, generateParams(method.In), " ", generateResults(method.Out), " {")
+
+	if len(values) > 0 {
+		g.P("if len(", delegates, ") == 0 {")
+		if returnsError(method) {
+			generateZeroReturn(g, method.Out, "nil")
+		} else {
+			generateZeroReturn(g, method.Out, "")
+		}
+		g.P("}")
+		g.P()
+	}
+
+	index, delegate := local.declare("i"), local.declare("delegate")
+
+	collected := make([]string, 0, len(values))
+	lhs := make([]string, 0, len(method.Out))
+	for _, value := range values {
+		slice := local.declare(value.Name + "s")
+		g.P(slice, " := make([]", value.Type, ", len(", delegates, "))")
+		collected = append(collected, slice)
+		lhs = append(lhs, slice+"["+index+"]")
+	}
+	errs := ""
+	if returnsError(method) {
+		errs = local.declare("errs")
+		g.P(errs, " := make([]error, len(", delegates, "))")
+		lhs = append(lhs, errs+"["+index+"]")
+	}
+
+	// the delegates take the context canceled on failures instead of the method one
+	ctx, ctxArg := "_", contextArg(method, names)
+	if ctxArg == "ctx" {
+		ctx = ctxArg
+	}
+
+	call := delegate + "." + method.Name + generateArgs(method.In)
+	if len(lhs) > 0 {
+		call = strings.Join(lhs, ", ") + " = " + call
+	}
+
+	g.P(recv, ".call(", ctxArg, ", func(", ctx, " ", names["context"], ".Context, ", index, " int, ", delegate, " ", typ, ") error {")
+	g.P(call)
+	g.P()
+	if errs != "" {
+		g.P("return ", errs, "[", index, "]")
+	} else {
+		g.P("return nil")
+	}
+	g.P("})")
+
+	if len(method.Out) == 0 {
+		g.P("}")
+		g.P()
+		return
+	}
+
+	selected := "0"
+	if cmd.multiSelect == MultiSelectLast {
+		selected = "len(" + delegates + ") - 1"
+	}
+
+	results := make([]string, 0, len(method.Out))
+	for _, slice := range collected {
+		results = append(results, slice+"["+selected+"]")
+	}
+	if errs != "" {
+		results = append(results, names["errors"]+".Join("+errs+"...)")
+	}
+
+	g.P()
+	g.P("return ", strings.Join(results, ", "))
+	g.P("}")
+	g.P()
+}
+
+// hasValues reports whether the method returns anything besides a trailing error.
+func hasValues(method model.Method) bool {
+	if returnsError(method) {
+		return len(method.Out) > 1
+	}
+
+	return len(method.Out) > 0
+}
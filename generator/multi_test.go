@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+)
+
+func TestGenerateMulti(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindMulti), parseExample(t), nil)
+
+	multi, ok := generated["multi.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate multi.go")
+	}
+
+	for _, want := range []string{
+		"type MultiTestInterface struct {",
+		"Delegates []in.TestInterface",
+		"Concurrent bool",
+		"ctx, cancel := context.WithCancel(ctx)",
+		"return result0s[0], errors.Join(errs...)",
+	} {
+		if !strings.Contains(multi, want) {
+			t.Errorf("multi.go lacks %q:\n%s", want, multi)
+		}
+	}
+}
+
+func TestGenerateMultiSelect(t *testing.T) {
+	for _, multiSelect := range []string{"", "middle"} {
+		cmd := newExampleCommand(KindMulti)
+		cmd.multiSelect = multiSelect
+
+		// E and F return non-error results
+		if _, err := cmd.Execute(parseExample(t)); err == nil {
+			t.Errorf("Execute() with multi selection policy %q error = nil, want an error", multiSelect)
+		}
+	}
+}
+
+func TestGenerateMultiCollisions(t *testing.T) {
+	// parameters and results are named like the identifiers multi methods declare
+	pkg := model.Package{
+		Name: "collide",
+		Imports: []model.Import{
+			{Alias: "context", Path: "context"},
+			{Alias: "collide", Path: "example.com/collide"},
+		},
+		Interfaces: []model.Interface{{
+			Name: "Collide",
+			Methods: []model.Method{
+				{
+					Name: "Sum",
+					In: []model.Parameter{
+						{Name: "ctx", Type: "context.Context", Kind: model.KindContext},
+						{Name: "i", Type: "int"},
+						{Name: "errs", Type: "[]error"},
+						{Name: "delegate", Type: "string"},
+						{Name: "counts", Type: "[]int"},
+					},
+					Out: []model.Parameter{{Name: "count", Type: "int"}, {Name: "err", Type: "error"}},
+				},
+				{
+					Name: "Put",
+					In:   []model.Parameter{{Name: "m", Type: "int"}, {Name: "cancel", Type: "string"}},
+				},
+			},
+		}},
+	}
+	stubs := map[string]string{"example.com/collide": `package collide
+
+import "context"
+
+type Collide interface {
+	Sum(ctx context.Context, i int, errs []error, delegate string, counts []int) (count int, err error)
+	Put(m int, cancel string)
+}
+`}
+
+	cmd := newExampleCommand(KindMulti)
+	cmd.interfaceName = "Collide"
+
+	generateExample(t, cmd, pkg, stubs)
+}
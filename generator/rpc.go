@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// rpcName returns the name of the net/rpc receiver struct for the interface.
+func rpcName(ifce model.Interface) string {
+	return "RPC" + ifce.Name
+}
+
+// rpcClientName returns the name of the net/rpc client struct for the interface.
+func rpcClientName(ifce model.Interface) string {
+	return "RPCClient" + ifce.Name
+}
+
+// generateRPC generates the net/rpc transport pair of the interface: a receiver exposing
+// the implementation methods with the request and response structs as arguments and replies,
+// and a client implementing the interface, both usable with JSON-RPC over any io.ReadWriteCloser.
+func (cmd *Command) generateRPC(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{
+		{Path: "context"},
+		{Path: "io"},
+		{Path: "net/rpc"},
+		{Path: "net/rpc/jsonrpc"},
+	}
+
+	file, err := cmd.generateFile(
+		pkg, ifce, "rpc.go", imports, func(g *protogen.GeneratedFile, names map[string]string) {
+			typ := interfaceType(pkg, ifce)
+			rpc, jsonrpc, io := names["net/rpc"], names["net/rpc/jsonrpc"], names["io"]
+			name := rpcName(ifce)
+			clientName := rpcClientName(ifce)
+			recv := newScope(ifce.Methods).declare("c")
+
+			g.P("// ", name, " exposes ", typ, " methods over net/rpc, see Register", name, ".")
+			g.P("type ", name, " struct {")
+			g.P("Impl ", typ)
+			g.P("}")
+			g.P()
+			g.P("// Register", name, " registers impl within server under the ", ifce.Name, " service name.")
+			g.P("func Register", name, "(server *", rpc, ".Server, impl ", typ, ") error {")
+			g.P("return server.RegisterName(\"", ifce.Name, "\", &", name, "{Impl: impl})")
+			g.P("}")
+			g.P()
+			g.P("// ServeJSON", name, " serves impl over JSON-RPC on conn, e.g. stdio of a plugin process,")
+			g.P("// and blocks until the client hangs up.")
+			g.P("func ServeJSON", name, "(conn ", io, ".ReadWriteCloser, impl ", typ, ") error {")
+			g.P("server := ", rpc, ".NewServer()")
+			g.P("if err := Register", name, "(server, impl); err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P()
+			g.P("server.ServeCodec(", jsonrpc, ".NewServerCodec(conn))")
+			g.P()
+			g.P("return nil")
+			g.P("}")
+			g.P()
+
+			for _, method := range ifce.Methods {
+				g.P("// ", method.Name, " calls ", typ, ".", method.Name, ".")
+				g.P("func (s *", name, ") ", method.Name, "(req ", requestName(method), ", res *", responseName(method), ") error {")
+				generateCall(g, method, "s.Impl", "req", names["context"]+".Background()", "res")
+				g.P()
+				if returnsError(method) {
+					g.P("return err")
+				} else {
+					g.P("return nil")
+				}
+				g.P("}")
+				g.P()
+			}
+
+			g.P("// ", clientName, " implements ", typ, " by calling ", name, " over net/rpc.")
+			g.P("// Methods without an error result panic when the call fails.")
+			g.P("type ", clientName, " struct {")
+			g.P("Client *", rpc, ".Client")
+			g.P("}")
+			g.P()
+			g.P("// NewJSON", clientName, " creates ", clientName, " talking JSON-RPC over conn.")
+			g.P("func NewJSON", clientName, "(conn ", io, ".ReadWriteCloser) ", clientName, " {")
+			g.P("return ", clientName, "{Client: ", jsonrpc, ".NewClient(conn)}")
+			g.P("}")
+			g.P()
+
+			for _, method := range ifce.Methods {
+				generateClientMethod(g, names, clientName, recv, method, ifce.Name+"."+method.Name)
+			}
+
+			g.P("// call calls the service method and waits for the reply unless ctx is done.")
+			g.P("func (", recv, " ", clientName, ") call(ctx ", names["context"], ".Context, method string, req, res any) error {")
+			g.P("call := ", recv, ".Client.Go(method, req, res, make(chan *", rpc, ".Call, 1))")
+			g.P()
+			g.P("select {")
+			g.P("case <-ctx.Done():")
+			g.P("return ctx.Err()")
+			g.P("case <-call.Done:")
+			g.P("return call.Error")
+			g.P("}")
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateRPC(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindRPC), parseExample(t), nil)
+
+	rpc, ok := generated["rpc.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate rpc.go")
+	}
+	if _, ok = generated["messages.go"]; !ok {
+		t.Error("Execute() didn't generate messages.go the rpc methods rely on")
+	}
+
+	for _, want := range []string{
+		"func RegisterRPCTestInterface(server *rpc.Server, impl in.TestInterface) error {",
+		"func (s *RPCTestInterface) E(req ERequest, res *EResponse) error {",
+		"res.Result0, err = s.Impl.E(context.Background(), req.Req)",
+		"func NewJSONRPCClientTestInterface(conn io.ReadWriteCloser) RPCClientTestInterface {",
+		`err := c.call(ctx, "TestInterface.E", ERequest{Req: req}, &resp)`,
+	} {
+		if !strings.Contains(rpc, want) {
+			t.Errorf("rpc.go lacks %q:\n%s", want, rpc)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"github.com/not-for-prod/implgen/model"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// switchName returns the name of the feature-flag switching struct for the interface.
+func switchName(ifce model.Interface) string {
+	return "Switch" + ifce.Name
+}
+
+// generateSwitch generates the feature-flag switching implementation: a struct holding
+// a new and an old implementation and routing every call to one of them using a selector.
+func (cmd *Command) generateSwitch(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{{Path: "context"}}
+
+	file, err := cmd.generateFile(
+		pkg, ifce, "switch.go", imports, func(g *protogen.GeneratedFile, names map[string]string) {
+			name := switchName(ifce)
+			typ := interfaceType(pkg, ifce)
+			recv := newScope(ifce.Methods).declare("s")
+
+			g.P("// ", name, " routes every call either to New or to Old implementation.")
+			g.P("type ", name, " struct {")
+			g.P("New ", typ)
+			g.P("Old ", typ)
+			g.P()
+			g.P("// Selector reports whether the method call is routed to New,")
+			g.P("// all calls are routed to Old when it isn't set.")
+			g.P("Selector func(ctx ", names["context"], ".Context, method string) bool")
+			g.P("}")
+			g.P()
+
+			for _, method := range ifce.Methods {
+				call := recv + ".pick(" + contextArg(method, names) + ", \"" + method.Name + "\")." +
+					method.Name + generateArgs(method.In)
+
+				g.P("func (", recv, " ", name, ") ", method.Name, generateParams(method.In), " ", generateResults(method.Out), " {")
+				if len(method.Out) > 0 {
+					g.P("return ", call)
+				} else {
+					g.P(call)
+				}
+				g.P("}")
+				g.P()
+			}
+
+			g.P("// pick returns the implementation the method call is routed to.")
+			g.P("func (", recv, " ", name, ") pick(ctx ", names["context"], ".Context, method string) ", typ, " {")
+			g.P("if ", recv, ".Selector != nil && ", recv, ".Selector(ctx, method) {")
+			g.P("return ", recv, ".New")
+			g.P("}")
+			g.P()
+			g.P("return ", recv, ".Old")
+			g.P("}")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.File{file}, nil
+}
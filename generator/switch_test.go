@@ -0,0 +1,26 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateSwitch(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindSwitch), parseExample(t), nil)
+
+	switchFile, ok := generated["switch.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate switch.go")
+	}
+
+	for _, want := range []string{
+		"type SwitchTestInterface struct {",
+		"Selector func(ctx context.Context, method string) bool",
+		`return s.pick(ctx, "E").E(ctx, req)`,
+		"func (s SwitchTestInterface) pick(ctx context.Context, method string) in.TestInterface {",
+	} {
+		if !strings.Contains(switchFile, want) {
+			t.Errorf("switch.go lacks %q:\n%s", want, switchFile)
+		}
+	}
+}
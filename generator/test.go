@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	stringCase "github.com/not-for-prod/implgen/pkg/string-case"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// generateTests generates table-driven test skeletons for the KindStub implementation methods,
+// following the singleFile layout: one test file or a test file next to every method file.
+func (cmd *Command) generateTests(pkg model.Package, ifce model.Interface) ([]model.File, error) {
+	imports := []model.Import{{Path: "reflect"}, {Path: "testing"}}
+
+	return cmd.generateTestFiles(pkg, ifce, "_test.go", imports, ifce.Methods, cmd.generateTest)
+}
+
+// generateTestFiles writes the test code generated by gen for methods following the singleFile
+// layout: one file named after the implementation or a file per method, both ending with suffix.
+func (cmd *Command) generateTestFiles(
+	pkg model.Package,
+	ifce model.Interface,
+	suffix string,
+	imports []model.Import,
+	methods []model.Method,
+	gen func(g *protogen.GeneratedFile, names map[string]string, method model.Method),
+) ([]model.File, error) {
+	if len(methods) == 0 {
+		return nil, nil
+	}
+
+	if cmd.singleFile {
+		name := stringCase.KebabCase(cmd.implementationName) + suffix
+
+		file, err := cmd.generateFile(
+			pkg, ifce, name, imports, func(g *protogen.GeneratedFile, names map[string]string) {
+				for _, method := range methods {
+					gen(g, names, method)
+				}
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return []model.File{file}, nil
+	}
+
+	files := make([]model.File, 0, len(methods))
+	for _, method := range methods {
+		name := stringCase.SnakeCase(method.Name) + suffix
+
+		file, err := cmd.generateFile(
+			pkg, ifce, name, imports, func(g *protogen.GeneratedFile, names map[string]string) {
+				gen(g, names, method)
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// generateTest writes the table-driven test of a single implementation method.
+func (cmd *Command) generateTest(g *protogen.GeneratedFile, names map[string]string, method model.Method) {
+	values := responseResults(method)
+
+	wants := make([]string, 0, len(values))
+	gots := make([]string, 0, len(method.Out))
+	for i := range values {
+		suffix := ""
+		if i > 0 {
+			suffix = strconv.Itoa(i)
+		}
+		wants = append(wants, "want"+suffix)
+		gots = append(gots, "got"+suffix)
+	}
+	if returnsError(method) {
+		gots = append(gots, "err")
+	}
+
+	args := make([]string, 0, len(method.In))
+	for _, param := range method.In {
+		arg := "tt.args." + paramName(param)
+		if strings.HasPrefix(param.Type, "...") {
+			arg += "..."
+		}
+		args = append(args, arg)
+	}
+	call := "i." + method.Name + "(" + strings.Join(args, ", ") + ")"
+
+	g.P("func Test", cmd.implementationName, "_", method.Name, "(t *", names["testing"], ".T) {")
+	g.P("t.Parallel()")
+	g.P()
+	if len(method.In) > 0 {
+		g.P("type args struct {")
+		for _, param := range method.In {
+			g.P(paramName(param), " ", fieldType(param))
+		}
+		g.P("}")
+		g.P()
+	}
+	g.P("tests := []struct {")
+	g.P("name string")
+	if len(method.In) > 0 {
+		g.P("args args")
+	}
+	for i, value := range values {
+		g.P(wants[i], " ", value.Type)
+	}
+	if returnsError(method) {
+		g.P("wantErr bool")
+	}
+	g.P("}{")
+	g.P("// TODO: add test cases.")
+	g.P("}")
+	g.P()
+	g.P("for _, tt := range tests {")
+	g.P("t.Run(tt.name, func(t *", names["testing"], ".T) {")
+	g.P("t.Parallel()")
+	g.P()
+	g.P("i := &", cmd.implementationName, "{}")
+	if len(gots) > 0 {
+		g.P(strings.Join(gots, ", "), " := ", call)
+	} else {
+		g.P(call)
+	}
+	if returnsError(method) {
+		g.P("if (err != nil) != tt.wantErr {")
+		g.P("t.Fatalf(\"", method.Name, "() error = %v, wantErr %v\", err, tt.wantErr)")
+		g.P("}")
+	}
+	for i := range values {
+		g.P("if !", names["reflect"], ".DeepEqual(", gots[i], ", tt.", wants[i], ") {")
+		g.P("t.Errorf(\"", method.Name, "() ", gots[i], " = %v, want %v\", ", gots[i], ", tt.", wants[i], ")")
+		g.P("}")
+	}
+	g.P("})")
+	g.P("}")
+	g.P("}")
+	g.P()
+}
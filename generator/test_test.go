@@ -0,0 +1,27 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateTests(t *testing.T) {
+	generated := generateExample(t, newExampleCommand(KindStub, KindTest), parseExample(t), nil)
+
+	test, ok := generated["e_test.go"]
+	if !ok {
+		t.Fatal("Execute() didn't generate e_test.go")
+	}
+
+	for _, want := range []string{
+		"func TestImplementation_E(t *testing.T) {",
+		"req in.ERequest",
+		"want    in.EResponse",
+		"got, err := i.E(tt.args.ctx, tt.args.req)",
+		"if !reflect.DeepEqual(got, tt.want) {",
+	} {
+		if !strings.Contains(test, want) {
+			t.Errorf("e_test.go lacks %q:\n%s", want, test)
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
 
+	"github.com/not-for-prod/implgen/config"
 	"github.com/not-for-prod/implgen/generator"
-	"github.com/not-for-prod/implgen/parser"
+	"github.com/not-for-prod/implgen/model"
 	"github.com/not-for-prod/implgen/pkg/clog"
-	"github.com/not-for-prod/implgen/writer"
+	"github.com/not-for-prod/implgen/pkg/implgen"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -19,12 +22,29 @@ const (
 	implementationNameFlag        = "impl-name"
 	implementationPackageNameFlag = "impl-package"
 	singleFileFlag                = "single-file"
+	kindFlag                      = "kind"
+	multiSelectFlag               = "multi-select"
+	grpcSrcFlag                   = "grpc-src"
+	grpcServerFlag                = "grpc-server"
+	depFlag                       = "dep"
+	depNilCheckFlag               = "dep-nil-check"
+	constructorFlag               = "constructor"
+	configFlag                    = "config"
+	overwriteFlag                 = "overwrite"
+	jobsFlag                      = "jobs"
+	dryRunFlag                    = "dry-run"
+	outputFlag                    = "output"
 	verboseFlag                   = "verbose"
 )
 
 const (
 	// Default values for optional flags
-	defaultImplementationName = "Implementation"
+	defaultImplementationName = implgen.DefaultImplementationName
+)
+
+var (
+	// defaultKinds are generated when --kind is not set
+	defaultKinds = implgen.DefaultKinds
 )
 
 // main defines and executes the CLI command using cobra.
@@ -36,34 +56,109 @@ func main() {
 		Short: "creates basic interface implementation",
 		Long: `This tool generates Go implementations for interfaces in the given source package.
 Example:
-  implgen --src=./service --dst=./serviceimpl --interface-name=Greeter`,
+  implgen --src=./service --dst=./serviceimpl --interface-name=Greeter
+
+Without --src, targets are read from the --config file, .implgen.yaml by default.
+With package patterns, interfaces annotated with //implgen:generate are generated:
+  implgen ./...`,
+		Args: cobra.ArbitraryArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			flags := cmd.Flags()
-			parseCommand := flagsToParseCommand(flags)
-			generateCommand := flagsToGenerateCommand(flags)
-			writeCommand := flagsToWriteCommand(flags)
-
-			_package, err := parseCommand.Execute()
-			exitOnErr("failed to parse source file", err)
-
-			// Run code generation using provided options
-			files, err := generateCommand.Execute(_package)
-			exitOnErr("failed to generate implementation", err)
-			
-			// Write generated files to disk
-			err = writeCommand.Execute(files)
-			exitOnErr("failed to write basic interfaces implementation", err)
+
+			targets, packages, err := resolveTargets(cmd.Context(), flags, args)
+			exitOnErr("failed to resolve targets", err)
+
+			if len(targets) == 0 {
+				clog.Warn("no interfaces annotated with //implgen:generate found")
+				return
+			}
+
+			opts := flagsToOptions(flags, targets, packages)
+			output, _ := flags.GetString(outputFlag)
+			dryRun, _ := flags.GetBool(dryRunFlag)
+
+			switch {
+			case output != "" && dryRun:
+				err = fmt.Errorf("--%s can't be used with --%s", outputFlag, dryRunFlag)
+			case output != "":
+				err = generateOutput(cmd.Context(), opts, output)
+			case dryRun:
+				err = generateDryRun(cmd.Context(), opts)
+			default:
+				_, err = implgen.Generate(cmd.Context(), opts)
+			}
+			exitOnErr("failed to generate", err)
 		},
 	}
+	cmd.AddCommand(newWatchCommand())
 
 	// Register command-line flags
 	registerFlags(cmd)
+	cmd.Flags().Bool(dryRunFlag, false, "print unified diffs of the files that would be written instead of writing them")
+	cmd.Flags().String(
+		outputFlag, "",
+		"emit all generated files instead of writing them: stdout, txtar or an archive path ending with .zip, .tar.gz or .tgz",
+	)
 
 	// Execute the root command
 	err := cmd.Execute()
 	exitOnErr("failed to execute command", err)
 }
 
+// resolveTargets returns targets of the interfaces annotated in packages matching patterns
+// along with their parsed packages, or the flags and config targets unless patterns are set.
+// Flags set explicitly take precedence over the directive arguments
+func resolveTargets(
+	ctx context.Context,
+	flags *pflag.FlagSet,
+	patterns []string,
+) ([]config.Target, map[string]model.Package, error) {
+	if len(patterns) == 0 {
+		targets, err := flagsToTargets(flags)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read config: %w", err)
+		}
+
+		return targets, nil, nil
+	}
+
+	targets, packages, err := implgen.Scan(ctx, ".", patterns)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan packages: %w", err)
+	}
+
+	base := flagsToTarget(flags, false)
+	overlay := flagsToTarget(flags, true)
+
+	for i := range targets {
+		targets[i] = base.Merge(targets[i]).Merge(overlay)
+	}
+
+	return targets, packages, nil
+}
+
+// flagsToOptions - parse cobra.Command flags into implgen.Options generating targets
+func flagsToOptions(
+	flags *pflag.FlagSet,
+	targets []config.Target,
+	packages map[string]model.Package,
+) implgen.Options {
+	verbose, _ := flags.GetBool(verboseFlag)
+	jobs, _ := flags.GetInt(jobsFlag)
+
+	opts := implgen.Options{
+		Targets:  targets,
+		Packages: packages,
+		Jobs:     jobs,
+		Verbose:  verbose,
+	}
+	if verbose {
+		opts.Logger = clog.Logger{}
+	}
+
+	return opts
+}
+
 func exitOnErr(msg string, err error) {
 	if err != nil {
 		clog.Errorf("%s: %v", msg, err)
@@ -73,69 +168,118 @@ func exitOnErr(msg string, err error) {
 
 // registerFlags - registers flags
 func registerFlags(cmd *cobra.Command) {
-	cmd.Flags().String(srcFlat, "", "source file")
-	cmd.Flags().String(dstFlat, "", "destination file")
-	_ = cmd.MarkFlagRequired(srcFlat)
-	_ = cmd.MarkFlagRequired(dstFlat)
-
-	cmd.Flags().String(interfaceNameFlag, "", "source interface name")
-	cmd.Flags().Bool(singleFileFlag, false, "generate interface methods into single file")
-	cmd.Flags().String(implementationNameFlag, defaultImplementationName, "generated implementation struct name")
-	cmd.Flags().String(
+	cmd.PersistentFlags().String(srcFlat, "", "source file, required unless targets are read from the config")
+	cmd.PersistentFlags().String(dstFlat, "", "destination file, required unless targets are read from the config")
+	cmd.PersistentFlags().String(configFlag, "", "config file listing generation targets, defaults to "+config.DefaultPath)
+
+	cmd.PersistentFlags().String(interfaceNameFlag, "", "source interface name")
+	cmd.PersistentFlags().Bool(singleFileFlag, false, "generate interface methods into single file")
+	cmd.PersistentFlags().String(implementationNameFlag, defaultImplementationName, "generated implementation struct name")
+	cmd.PersistentFlags().String(
 		implementationPackageNameFlag, "",
 		"generated implementation package name, can be used only when interface name is set",
 	)
-	cmd.Flags().Bool(verboseFlag, false, "enable verbose logging")
+	cmd.PersistentFlags().StringSlice(kindFlag, defaultKinds, "kinds of generated output: stub, funcs, middleware, multi, fallback, switch, grpc, messages, http, client, rpc, cli, test, contract, bench, fuzz, fx, wire")
+	cmd.PersistentFlags().String(
+		multiSelectFlag, "",
+		"delegate results returned by multi methods with non-error results: first, last",
+	)
+	cmd.PersistentFlags().String(grpcSrcFlag, "", "protoc-generated gRPC service source file, used by grpc kind")
+	cmd.PersistentFlags().String(
+		grpcServerFlag, "",
+		"protoc-generated gRPC server interface name, defaults to the only one embedding UnimplementedXxxServer",
+	)
+	cmd.PersistentFlags().StringArray(
+		depFlag, nil,
+		"implementation dependency as name=type, e.g. repo=github.com/acme/app/ports.UserRepo, repeat to set several",
+	)
+	cmd.PersistentFlags().Bool(depNilCheckFlag, false, "make the implementation constructor return an error on nil dependencies")
+	cmd.PersistentFlags().String(
+		constructorFlag, generator.ConstructorPositional,
+		"implementation constructor style: positional dependency parameters or functional options",
+	)
+	cmd.PersistentFlags().String(
+		overwriteFlag, "",
+		"policy for existing files: never, always, prompt, sync; defaults to prompt with verbose, never otherwise",
+	)
+	cmd.PersistentFlags().Int(jobsFlag, 0, "number of targets generated concurrently, defaults to GOMAXPROCS")
+	cmd.PersistentFlags().Bool(verboseFlag, false, "enable verbose logging")
 }
 
-// flagsToParseCommand - parse cobra.Command flags into parser.Command
-func flagsToParseCommand(flags *pflag.FlagSet) *parser.Command {
-	// Parse required --src flag
-	src, err := flags.GetString(srcFlat)
-	if err != nil {
-		clog.Errorf("failed to get src: %v", err)
-		os.Exit(1)
-	}
+// flagsToTargets - parse cobra.Command flags into targets, read from the config file unless --src is set.
+// Flags set explicitly take precedence over the config targets and defaults
+func flagsToTargets(flags *pflag.FlagSet) ([]config.Target, error) {
+	configPath, _ := flags.GetString(configFlag)
 
-	return parser.NewCommand(src)
-}
+	if configPath == "" && !flags.Changed(srcFlat) {
+		if _, err := os.Stat(config.DefaultPath); err == nil {
+			configPath = config.DefaultPath
+		}
+	}
 
-// flagsToGenerateCommand - parse cobra.Command flags into generator.Command
-func flagsToGenerateCommand(flags *pflag.FlagSet) *generator.Command {
-	// Parse required --dst flag
-	dst, err := flags.GetString(dstFlat)
-	if err != nil {
-		clog.Errorf("failed to get dst: %v", err)
-		os.Exit(1)
+	if configPath == "" {
+		return []config.Target{flagsToTarget(flags, false)}, nil
 	}
 
-	// Parse optional flags
-	interfaceName, err := flags.GetString(interfaceNameFlag)
+	cfg, err := config.Load(configPath)
 	if err != nil {
-		clog.Errorf("failed to get interface-name: %v", err)
-		os.Exit(1)
+		return nil, err
 	}
-	singleFile, _ := flags.GetBool(singleFileFlag)
-	implementationName, _ := flags.GetString(implementationNameFlag)
-	implementationPackageName, _ := flags.GetString(implementationPackageNameFlag)
 
-	// Validate: impl package name requires interface name
-	if implementationPackageName != "" && interfaceName == "" {
-		clog.Errorf("flag %q requires %q to be set", implementationPackageNameFlag, interfaceNameFlag)
-		os.Exit(1)
+	base := flagsToTarget(flags, false)
+	overlay := flagsToTarget(flags, true)
+
+	targets := make([]config.Target, 0, len(cfg.Targets))
+	for _, target := range cfg.Resolve() {
+		targets = append(targets, base.Merge(target).Merge(overlay))
 	}
 
-	return generator.NewCommand(
-		dst,
-		interfaceName,             // src interface name
-		implementationName,        // dst struct name
-		implementationPackageName, // dst package name
-		singleFile,
-	)
+	return targets, nil
 }
 
-func flagsToWriteCommand(flags *pflag.FlagSet) *writer.Command {
-	verbose, _ := flags.GetBool(verboseFlag)
+// flagsToTarget - parse cobra.Command flags into config.Target, with changedOnly flags left
+// at their defaults are skipped
+func flagsToTarget(flags *pflag.FlagSet, changedOnly bool) config.Target {
+	target := config.Target{}
+
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if changedOnly && !flag.Changed {
+			return
+		}
+
+		switch flag.Name {
+		case srcFlat:
+			target.Src, _ = flags.GetString(flag.Name)
+		case dstFlat:
+			target.Dst, _ = flags.GetString(flag.Name)
+		case interfaceNameFlag:
+			target.InterfaceName, _ = flags.GetString(flag.Name)
+		case implementationNameFlag:
+			target.ImplementationName, _ = flags.GetString(flag.Name)
+		case implementationPackageNameFlag:
+			target.ImplementationPackageName, _ = flags.GetString(flag.Name)
+		case singleFileFlag:
+			singleFile, _ := flags.GetBool(flag.Name)
+			target.SingleFile = &singleFile
+		case kindFlag:
+			target.Kinds, _ = flags.GetStringSlice(flag.Name)
+		case multiSelectFlag:
+			target.MultiSelect, _ = flags.GetString(flag.Name)
+		case grpcSrcFlag:
+			target.GRPCSrc, _ = flags.GetString(flag.Name)
+		case grpcServerFlag:
+			target.GRPCServer, _ = flags.GetString(flag.Name)
+		case depFlag:
+			target.Deps, _ = flags.GetStringArray(flag.Name)
+		case depNilCheckFlag:
+			depNilCheck, _ := flags.GetBool(flag.Name)
+			target.DepNilCheck = &depNilCheck
+		case constructorFlag:
+			target.Constructor, _ = flags.GetString(flag.Name)
+		case overwriteFlag:
+			target.Overwrite, _ = flags.GetString(flag.Name)
+		}
+	})
 
-	return writer.NewCommand(verbose)
+	return target
 }
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"strings"
+)
+
+// Dependency is a field of the generated implementation set by its constructor
+type Dependency struct {
+	Name string
+	// Type is the field type qualified with the Import alias, e.g. *slog.Logger
+	Type string
+	// Import is the package of Type set by a full import path, empty otherwise
+	Import Import
+	// Nillable reports whether the constructor can check the dependency for nil
+	Nillable bool
+	// Default is the expression the dependency is set to by options constructors, optional
+	Default string
+}
+
+// nillablePrefixes are prefixes of type expressions comparable with nil
+var nillablePrefixes = []string{"*", "[]", "map[", "chan ", "<-chan ", "func(", "interface{"}
+
+// ParseDependency parses the `name=type[=default]` dependency spec, the type can be qualified
+// with a full import path, e.g. repo=github.com/acme/app/ports.UserRepo.
+// Named types of other packages are assumed to be interfaces, hence nillable.
+func ParseDependency(spec string) (Dependency, error) {
+	name, typ, ok := strings.Cut(strings.TrimSpace(spec), "=")
+	typ, value, _ := strings.Cut(typ, "=")
+	if !ok || !token.IsIdentifier(name) || typ == "" {
+		return Dependency{}, fmt.Errorf("invalid dependency %q, expected name=type", spec)
+	}
+
+	dep := Dependency{Name: name, Type: typ, Default: value}
+
+	// split modifiers, e.g. `*` or `[]`, off the qualified type name
+	i := strings.IndexFunc(typ, func(r rune) bool { return r != '*' && r != '[' && r != ']' })
+	if i < 0 {
+		return Dependency{}, fmt.Errorf("invalid dependency %q, expected name=type", spec)
+	}
+	modifiers, qualified := typ[:i], typ[i:]
+
+	if slash := strings.LastIndex(qualified, "/"); slash >= 0 {
+		dot := strings.LastIndex(qualified, ".")
+		if dot < slash {
+			return Dependency{}, fmt.Errorf("invalid dependency %q, expected import/path.Type", spec)
+		}
+
+		dep.Import = Import{Alias: path.Base(qualified[:dot]), Path: qualified[:dot]}
+		dep.Type = modifiers + dep.Import.Alias + qualified[dot:]
+	}
+
+	for _, prefix := range nillablePrefixes {
+		if strings.HasPrefix(dep.Type, prefix) {
+			dep.Nillable = true
+		}
+	}
+	if strings.Contains(dep.Type, ".") || dep.Type == "error" || dep.Type == "any" {
+		dep.Nillable = true
+	}
+
+	return dep, nil
+}
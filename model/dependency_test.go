@@ -0,0 +1,79 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+)
+
+func TestParseDependency(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    model.Dependency
+		wantErr bool
+	}{
+		{
+			name: "builtin type",
+			spec: "limit=int",
+			want: model.Dependency{Name: "limit", Type: "int"},
+		},
+		{
+			name: "pointer",
+			spec: "log=*slog.Logger",
+			want: model.Dependency{Name: "log", Type: "*slog.Logger", Nillable: true},
+		},
+		{
+			name: "default",
+			spec: "log=*slog.Logger=slog.Default()",
+			want: model.Dependency{Name: "log", Type: "*slog.Logger", Nillable: true, Default: "slog.Default()"},
+		},
+		{
+			name: "import path",
+			spec: "repo=github.com/acme/app/ports.UserRepo",
+			want: model.Dependency{
+				Name:     "repo",
+				Type:     "ports.UserRepo",
+				Import:   model.Import{Alias: "ports", Path: "github.com/acme/app/ports"},
+				Nillable: true,
+			},
+		},
+		{
+			name: "import path with modifiers",
+			spec: "users=[]*github.com/acme/app/domain.User",
+			want: model.Dependency{
+				Name:     "users",
+				Type:     "[]*domain.User",
+				Import:   model.Import{Alias: "domain", Path: "github.com/acme/app/domain"},
+				Nillable: true,
+			},
+		},
+		{
+			name: "map",
+			spec: "cache=map[string]int",
+			want: model.Dependency{Name: "cache", Type: "map[string]int", Nillable: true},
+		},
+		{
+			name: "error",
+			spec: "fail=error",
+			want: model.Dependency{Name: "fail", Type: "error", Nillable: true},
+		},
+		{name: "no type", spec: "repo", wantErr: true},
+		{name: "empty type", spec: "repo=", wantErr: true},
+		{name: "invalid name", spec: "user-repo=ports.UserRepo", wantErr: true},
+		{name: "only modifiers", spec: "repo=*", wantErr: true},
+		{name: "import path without type", spec: "repo=github.com/acme/app/ports", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := model.ParseDependency(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDependency(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDependency(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
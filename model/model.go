@@ -16,6 +16,11 @@ type Import struct {
 type Interface struct {
 	Name    string
 	Methods []Method
+	// Embeds lists types embedded into generated implementations,
+	// e.g. UnimplementedXxxServer required by protoc-gen-go-grpc interfaces
+	Embeds []string
+	// Deps lists dependencies declared by the `//implgen:deps` directive
+	Deps []Dependency
 }
 
 type Method struct {
@@ -27,6 +32,30 @@ type Method struct {
 type Parameter struct {
 	Name string
 	Type string
+	// Kind classifies the underlying type, KindOther when unknown
+	Kind Kind
+	// Basic is the underlying basic type name, e.g. int64 for `type ID int64`,
+	// or []byte for KindBytes, empty for other kinds
+	Basic string
+}
+
+// Kind classifies parameter types by their underlying type
+type Kind int
+
+const (
+	KindOther Kind = iota
+	KindContext
+	KindBool
+	KindString
+	KindBytes
+	KindInt
+	KindUint
+	KindFloat
+)
+
+// Fuzzable reports whether values of the kind can be passed to testing.F fuzz targets
+func (k Kind) Fuzzable() bool {
+	return k >= KindBool
 }
 
 type File struct {
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	"github.com/not-for-prod/implgen/pkg/implgen"
+	"github.com/not-for-prod/implgen/writer"
+)
+
+const (
+	// Values of the output flag besides archive file paths
+	outputStdout = "stdout"
+	outputTxtar  = "txtar"
+)
+
+// generateDryRun generates targets into memory over the working tree
+// and prints unified diffs of the files that would be written
+func generateDryRun(ctx context.Context, opts implgen.Options) error {
+	overlay := writer.NewOverlay(writer.OS{})
+	opts.FS = overlay
+
+	_, genErr := implgen.Generate(ctx, opts)
+
+	diff, err := overlay.Diff()
+	if err != nil {
+		return errors.Join(genErr, fmt.Errorf("failed to diff: %w", err))
+	}
+
+	_, err = os.Stdout.Write(diff)
+
+	return errors.Join(genErr, err)
+}
+
+// generateOutput generates targets into memory and emits all of their files to output:
+// stdout, txtar or an archive path ending with .zip, .tar.gz or .tgz.
+// Existing files and overwrite policies are ignored, nothing is emitted when any target fails
+func generateOutput(ctx context.Context, opts implgen.Options, output string) error {
+	write, err := outputWriter(output)
+	if err != nil {
+		return err
+	}
+
+	opts.FS = writer.NewMemory()
+	opts.Logger = nil
+
+	result, err := implgen.Generate(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	files := result.Files
+	if output != outputStdout {
+		// archives hold paths relative to the common root of the files
+		files, err = writer.RelFiles(files)
+		if err != nil {
+			return err
+		}
+	}
+
+	if output == outputStdout || output == outputTxtar {
+		return write(os.Stdout, files)
+	}
+
+	return writeArchiveFile(output, files, write)
+}
+
+// outputWriter returns the function writing files in the format of output
+func outputWriter(output string) (func(w io.Writer, files []model.File) error, error) {
+	switch {
+	case output == outputStdout:
+		return writer.WriteConcatenated, nil
+	case output == outputTxtar:
+		return writer.WriteTxtar, nil
+	case strings.HasSuffix(output, ".zip"):
+		return writer.WriteZip, nil
+	case strings.HasSuffix(output, ".tar.gz"), strings.HasSuffix(output, ".tgz"):
+		return writer.WriteTarGz, nil
+	default:
+		return nil, fmt.Errorf("unknown output %q, expected stdout, txtar or a .zip, .tar.gz or .tgz path", output)
+	}
+}
+
+// writeArchiveFile creates the archive file with path and writes files into it
+func writeArchiveFile(path string, files []model.File, write func(w io.Writer, files []model.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	if err = write(f, files); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return f.Close()
+}
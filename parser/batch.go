@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"slices"
+
+	"github.com/not-for-prod/implgen/model"
+	"golang.org/x/tools/go/packages"
+)
+
+// BatchCommand parses many source files loading their packages at once per module,
+// so that type checking of shared dependencies isn't repeated for every file
+type BatchCommand struct {
+	ctx  context.Context
+	srcs []string
+}
+
+func NewBatchCommand(ctx context.Context, srcs []string) *BatchCommand {
+	return &BatchCommand{
+		ctx:  ctx,
+		srcs: srcs,
+	}
+}
+
+// Execute returns packages parsed from the source files keyed by their paths as given,
+// errors of all files are joined in the order of the paths
+func (cmd *BatchCommand) Execute() (map[string]model.Package, error) {
+	result, errs, err := cmd.ExecuteEach()
+	if err != nil {
+		return nil, err
+	}
+
+	var joined []error
+	for _, src := range cmd.srcs {
+		if err := errs[src]; err != nil {
+			joined = append(joined, fmt.Errorf("%s: %w", src, err))
+			delete(errs, src)
+		}
+	}
+
+	return result, errors.Join(joined...)
+}
+
+// ExecuteEach returns packages parsed from the source files along with errors of the failed ones,
+// both keyed by their paths as given, so that a broken file doesn't fail the others
+func (cmd *BatchCommand) ExecuteEach() (map[string]model.Package, map[string]error, error) {
+	// group absolute paths by module root, files outside modules are loaded from their dirs
+	modules := make(map[string][]string)
+	abs := make(map[string]string, len(cmd.srcs))
+	srcs := make([]string, 0, len(cmd.srcs))
+
+	for _, src := range cmd.srcs {
+		if _, ok := abs[src]; ok {
+			continue
+		}
+		srcs = append(srcs, src)
+
+		path, err := filepath.Abs(src)
+		if err != nil {
+			return nil, nil, err
+		}
+		abs[src] = path
+
+		root, _ := findGoModRoot(filepath.Dir(path))
+		if root == "" {
+			root = filepath.Dir(path)
+		}
+		if !slices.Contains(modules[root], path) {
+			modules[root] = append(modules[root], path)
+		}
+	}
+
+	files := make(map[string]loadedFile, len(abs))
+	errs := make(map[string]error)
+
+	for root, paths := range modules {
+		patterns := make([]string, 0, len(paths))
+		for _, path := range paths {
+			patterns = append(patterns, "file="+path)
+		}
+
+		pkgs, err := packages.Load(&packages.Config{Context: cmd.ctx, Mode: loadMode, Dir: root}, patterns...)
+		if err != nil {
+			for _, path := range paths {
+				errs[path] = fmt.Errorf("failed to load package: %w", err)
+			}
+			continue
+		}
+
+		for _, pkg := range pkgs {
+			for _, astFile := range pkg.Syntax {
+				path := pkg.Fset.File(astFile.Pos()).Name()
+				if slices.Contains(paths, path) {
+					files[path] = loadedFile{pkg: pkg, astFile: astFile}
+				}
+			}
+		}
+	}
+
+	result := make(map[string]model.Package, len(cmd.srcs))
+	srcErrs := make(map[string]error)
+
+	for _, src := range srcs {
+		_package, err := parseLoaded(files, errs, abs[src])
+		if err != nil {
+			srcErrs[src] = err
+			continue
+		}
+
+		result[src] = _package
+	}
+
+	return result, srcErrs, nil
+}
+
+// loadedFile is a source file along with its loaded package
+type loadedFile struct {
+	pkg     *packages.Package
+	astFile *ast.File
+}
+
+// parseLoaded parses the loaded file with path, reporting its package load errors
+func parseLoaded(files map[string]loadedFile, errs map[string]error, path string) (model.Package, error) {
+	if err := errs[path]; err != nil {
+		return model.Package{}, err
+	}
+
+	file, ok := files[path]
+	if !ok {
+		return model.Package{}, errors.New("package not found")
+	}
+
+	if err := loadErrors(file.pkg); err != nil {
+		return model.Package{}, err
+	}
+
+	return NewCommand(path).parseFile(file.pkg, file.astFile)
+}
+
+// loadErrors joins errors of the loaded package, errors of its dependencies aren't included
+func loadErrors(pkg *packages.Package) error {
+	if len(pkg.Errors) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(pkg.Errors))
+	for _, err := range pkg.Errors {
+		errs = append(errs, err)
+	}
+
+	return fmt.Errorf("package load errors: %w", errors.Join(errs...))
+}
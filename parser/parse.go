@@ -8,14 +8,21 @@ import (
 	"go/types"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/not-for-prod/implgen/model"
 	"golang.org/x/tools/go/packages"
 )
 
+// depsDirective declares dependencies of generated implementations in the interface doc comment,
+// e.g. `//implgen:deps repo=ports.UserRepo log=*slog.Logger`
+const depsDirective = "//implgen:deps"
+
 type Command struct {
 	src        string
+	fset       *token.FileSet
+	types      *types.Package
 	info       *types.Info
 	selfImport model.Import
 	imports    []model.Import
@@ -27,14 +34,17 @@ func NewCommand(src string) *Command {
 	}
 }
 
+// loadMode loads syntax and type info required to parse interfaces
+const loadMode = packages.NeedName |
+	packages.NeedSyntax |
+	packages.NeedTypes |
+	packages.NeedTypesInfo |
+	packages.NeedImports
+
 func (cmd *Command) Execute() (model.Package, error) {
 	cfg := &packages.Config{
-		Mode: packages.NeedName |
-			packages.NeedSyntax |
-			packages.NeedTypes |
-			packages.NeedTypesInfo |
-			packages.NeedImports,
-		Dir: filepath.Dir(cmd.src), // important: evaluate from your file’s directory
+		Mode: loadMode,
+		Dir:  filepath.Dir(cmd.src), // important: evaluate from your file’s directory
 	}
 
 	abs, err := filepath.Abs(cmd.src)
@@ -68,19 +78,34 @@ func (cmd *Command) Execute() (model.Package, error) {
 		return model.Package{}, fmt.Errorf("package not found")
 	}
 
+	return cmd.parseFile(pkg, astFile)
+}
+
+// parseFile parses interfaces of the loaded package file
+func (cmd *Command) parseFile(pkg *packages.Package, astFile *ast.File) (model.Package, error) {
 	// add self import
 	selfImport := model.Import{
 		Alias: astFile.Name.Name, // package name as alias
-		Path:  packageImportPath(),
+		Path:  pkg.PkgPath,
+	}
+	if selfImport.Path == "" || selfImport.Path == "command-line-arguments" {
+		selfImport.Path = packageImportPath(filepath.Dir(pkg.Fset.File(astFile.Pos()).Name()))
 	}
 	cmd.imports = append(cmd.imports, selfImport)
 
+	cmd.fset = pkg.Fset
+	cmd.types = pkg.Types
 	cmd.info = pkg.TypesInfo
 	cmd.imports = append(cmd.imports, cmd.parseImports(astFile)...)
 
+	interfaces, err := cmd.parseInterfaces(astFile)
+	if err != nil {
+		return model.Package{}, err
+	}
+
 	return model.Package{
 		Name:       pkg.Name,
-		Interfaces: cmd.parseInterfaces(astFile),
+		Interfaces: interfaces,
 		Imports:    cmd.imports,
 	}, nil
 }
@@ -108,7 +133,7 @@ func (cmd *Command) parseImports(node *ast.File) []model.Import {
 	return imports
 }
 
-func (cmd *Command) parseInterfaces(node *ast.File) []model.Interface {
+func (cmd *Command) parseInterfaces(node *ast.File) ([]model.Interface, error) {
 	var interfaces []model.Interface
 
 	for _, decl := range node.Decls {
@@ -119,11 +144,75 @@ func (cmd *Command) parseInterfaces(node *ast.File) []model.Interface {
 		for _, spec := range gen.Specs {
 			tspec := spec.(*ast.TypeSpec)
 			if iface, ok := tspec.Type.(*ast.InterfaceType); ok {
-				interfaces = append(interfaces, cmd.parseInterface(tspec.Name.Name, iface))
+				deps, err := cmd.parseDeps(typeDoc(gen, tspec))
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse %s dependencies: %w", tspec.Name.Name, err)
+				}
+
+				_interface := cmd.parseInterface(tspec.Name.Name, iface)
+				_interface.Deps = deps
+				interfaces = append(interfaces, _interface)
 			}
 		}
 	}
-	return interfaces
+	return interfaces, nil
+}
+
+// typeDoc returns the doc comment of the type, written above the whole declaration
+// unless it is a group of several types
+func typeDoc(gen *ast.GenDecl, tspec *ast.TypeSpec) *ast.CommentGroup {
+	if tspec.Doc == nil && len(gen.Specs) == 1 {
+		return gen.Doc
+	}
+
+	return tspec.Doc
+}
+
+// parseDeps parses dependencies declared by depsDirective lines of the doc comment,
+// types resolved within the package are qualified like parameter types and checked
+// for nil only when they are nillable
+func (cmd *Command) parseDeps(doc *ast.CommentGroup) ([]model.Dependency, error) {
+	if doc == nil {
+		return nil, nil
+	}
+
+	var deps []model.Dependency
+
+	for _, comment := range doc.List {
+		specs, ok := strings.CutPrefix(comment.Text, depsDirective)
+		if !ok || specs != "" && specs[0] != ' ' && specs[0] != '\t' {
+			continue
+		}
+
+		for _, spec := range strings.Fields(specs) {
+			dep, err := model.ParseDependency(spec)
+			if err != nil {
+				return nil, err
+			}
+
+			if dep.Import.Path == "" {
+				tv, err := types.Eval(cmd.fset, cmd.types, comment.Pos(), dep.Type)
+				if err == nil && tv.IsType() {
+					dep.Type = types.TypeString(tv.Type, func(p *types.Package) string { return p.Name() })
+					dep.Nillable = nillable(tv.Type)
+				}
+			}
+
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps, nil
+}
+
+// nillable reports whether values of the type can be compared with nil
+func nillable(typ types.Type) bool {
+	switch typ.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return true
+	}
+
+	return false
 }
 
 func (cmd *Command) parseInterface(name string, iface *ast.InterfaceType) model.Interface {
@@ -148,35 +237,61 @@ func (cmd *Command) parseInterface(name string, iface *ast.InterfaceType) model.
 }
 
 func (cmd *Command) parseMethod(name string, ftype *ast.FuncType) model.Method {
-	method := model.Method{Name: name}
-
-	if ftype.Params != nil {
-		for i, param := range ftype.Params.List {
-			typ := cmd.exprString(param.Type)
-			if len(param.Names) == 0 {
-				method.In = append(method.In, model.Parameter{Name: "arg" + string(rune(i+'a')), Type: typ})
-			} else {
-				for _, name := range param.Names {
-					method.In = append(method.In, model.Parameter{Name: name.Name, Type: typ})
-				}
+	// names declared by the method, generated ones must not collide with them
+	declared := make(map[string]bool)
+	for _, fields := range []*ast.FieldList{ftype.Params, ftype.Results} {
+		if fields == nil {
+			continue
+		}
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				declared[name.Name] = true
 			}
 		}
 	}
 
-	if ftype.Results != nil {
-		for i, result := range ftype.Results.List {
-			typ := cmd.exprString(result.Type)
-			if len(result.Names) == 0 {
-				method.Out = append(method.Out, model.Parameter{Name: "ret" + string(rune(i+'a')), Type: typ})
-			} else {
-				for _, name := range result.Names {
-					method.Out = append(method.Out, model.Parameter{Name: name.Name, Type: typ})
+	return model.Method{
+		Name: name,
+		In:   cmd.parseParams(ftype.Params, "arg", declared),
+		Out:  cmd.parseParams(ftype.Results, "result", declared),
+	}
+}
+
+// parseParams flattens the field list into parameters, unnamed and blank ones are named
+// with prefix followed by their position, the next free position when the name is declared
+func (cmd *Command) parseParams(fields *ast.FieldList, prefix string, declared map[string]bool) []model.Parameter {
+	if fields == nil {
+		return nil
+	}
+
+	var params []model.Parameter
+
+	for _, field := range fields.List {
+		typ := cmd.exprString(field.Type)
+		kind, basic := cmd.classify(field.Type)
+
+		names := make([]string, 0, max(len(field.Names), 1))
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		if len(names) == 0 {
+			names = append(names, "")
+		}
+
+		for _, name := range names {
+			if name == "" || name == "_" {
+				name = prefix + strconv.Itoa(len(params))
+				for i := len(params) + 1; declared[name]; i++ {
+					name = prefix + strconv.Itoa(i)
 				}
+				declared[name] = true
 			}
+
+			params = append(params, model.Parameter{Name: name, Type: typ, Kind: kind, Basic: basic})
 		}
 	}
 
-	return method
+	return params
 }
 
 func (cmd *Command) exprString(expr ast.Expr) string {
@@ -211,6 +326,14 @@ func (cmd *Command) exprString(expr ast.Expr) string {
 		return "..." + cmd.exprString(e.Elt)
 	case *ast.MapType:
 		return "map[" + cmd.exprString(e.Key) + "]" + cmd.exprString(e.Value)
+	case *ast.IndexExpr:
+		return cmd.exprString(e.X) + "[" + cmd.exprString(e.Index) + "]"
+	case *ast.IndexListExpr:
+		indices := make([]string, 0, len(e.Indices))
+		for _, index := range e.Indices {
+			indices = append(indices, cmd.exprString(index))
+		}
+		return cmd.exprString(e.X) + "[" + strings.Join(indices, ", ") + "]"
 	case *ast.FuncType:
 		return "func" // Simplified
 	default:
@@ -218,24 +341,61 @@ func (cmd *Command) exprString(expr ast.Expr) string {
 	}
 }
 
-func packageImportPath() string {
-	// Get path where implgen is executed
-	pwd, err := os.Getwd()
-	if err != nil {
-		panic(err)
+// classify returns the kind of the type expression and its underlying basic type name using type info
+func (cmd *Command) classify(expr ast.Expr) (model.Kind, string) {
+	typ := cmd.info.TypeOf(expr)
+	if typ == nil {
+		return model.KindOther, ""
+	}
+
+	if named, ok := typ.(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context" {
+			return model.KindContext, ""
+		}
 	}
 
-	modRoot, _ := findGoModRoot(pwd)
+	switch underlying := typ.Underlying().(type) {
+	case *types.Basic:
+		info := underlying.Info()
+		switch {
+		case info&types.IsBoolean != 0:
+			return model.KindBool, underlying.Name()
+		case info&types.IsString != 0:
+			return model.KindString, underlying.Name()
+		case info&types.IsInteger != 0 && info&types.IsUnsigned != 0 && underlying.Kind() != types.Uintptr:
+			return model.KindUint, underlying.Name()
+		case info&types.IsInteger != 0 && info&types.IsUnsigned == 0:
+			return model.KindInt, underlying.Name()
+		case info&types.IsFloat != 0:
+			return model.KindFloat, underlying.Name()
+		}
+	case *types.Slice:
+		if elem, ok := underlying.Elem().Underlying().(*types.Basic); ok && elem.Kind() == types.Byte {
+			return model.KindBytes, "[]byte"
+		}
+	}
+
+	return model.KindOther, ""
+}
+
+// packageImportPath returns the import path of the package in dir
+// when it can't be taken from the loaded package.
+func packageImportPath(dir string) string {
+	modRoot, _ := findGoModRoot(dir)
 	if modRoot != "" {
 		// go.mod module name
 		modName, _ := moduleName(modRoot)
-		dir, _ := filepath.Rel(modRoot, pwd)
+		rel, _ := filepath.Rel(modRoot, dir)
+		if rel == "." {
+			return modName
+		}
 
-		return modName + "/" + dir
+		return modName + "/" + filepath.ToSlash(rel)
 	}
 
 	// Option B: fallback to directory name
-	return filepath.Base(pwd)
+	return filepath.Base(dir)
 }
 
 func moduleName(modRoot string) (string, error) {
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+)
+
+func TestExecuteSelfImport(t *testing.T) {
+	pkg, err := NewCommand("../example/in/interface.go").Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := "github.com/not-for-prod/implgen/example/in"
+	for _, imp := range pkg.Imports {
+		if imp.Alias == pkg.Name {
+			if imp.Path != want {
+				t.Errorf("Execute() self import path = %s, want %s", imp.Path, want)
+			}
+			return
+		}
+	}
+	t.Errorf("Execute() imports = %v, want the self import %s", pkg.Imports, want)
+}
+
+func TestPackageImportPath(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{name: "module root", dir: root, want: "example.com/app"},
+		{name: "nested dir", dir: filepath.Join(root, "internal", "ports"), want: "example.com/app/internal/ports"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := packageImportPath(tt.dir); got != tt.want {
+				t.Errorf("packageImportPath(%s) = %s, want %s", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteDeps(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n",
+		"service.go": `package app
+
+import "log/slog"
+
+type Repo interface{ Get(id int) string }
+
+type Config struct{ Log *slog.Logger }
+
+// Service serves users.
+//
+//implgen:deps repo=Repo log=*slog.Logger
+//implgen:deps cfg=Config api=example.com/app/api.Client
+type Service interface {
+	Serve(id int) error
+}
+`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkg, err := NewCommand(filepath.Join(dir, "service.go")).Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	i := slices.IndexFunc(pkg.Interfaces, func(ifce model.Interface) bool { return ifce.Name == "Service" })
+	if i < 0 {
+		t.Fatalf("Execute() interfaces = %v, want Service", pkg.Interfaces)
+	}
+
+	want := []model.Dependency{
+		{Name: "repo", Type: "app.Repo", Nillable: true},
+		{Name: "log", Type: "*slog.Logger", Nillable: true},
+		{Name: "cfg", Type: "app.Config"},
+		{
+			Name:     "api",
+			Type:     "api.Client",
+			Import:   model.Import{Alias: "api", Path: "example.com/app/api"},
+			Nillable: true,
+		},
+	}
+	if got := pkg.Interfaces[i].Deps; !slices.Equal(got, want) {
+		t.Errorf("Execute() deps = %+v, want %+v", got, want)
+	}
+}
+
+func TestExecuteGeneratedNames(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/app\n",
+		"store.go": `package app
+
+type Store interface {
+	Size() (_ int, err error)
+	Put(_ string, _ []byte) error
+	Get(_ int, arg0 string) (string, bool)
+}
+`,
+	})
+
+	pkg, err := NewCommand(filepath.Join(dir, "store.go")).Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(pkg.Interfaces) != 1 {
+		t.Fatalf("Execute() interfaces = %v, want Store", pkg.Interfaces)
+	}
+
+	want := map[string][2][]string{
+		"Size": {nil, {"result0", "err"}},
+		"Put":  {{"arg0", "arg1"}, {"result0"}},
+		"Get":  {{"arg1", "arg0"}, {"result0", "result1"}},
+	}
+	for _, method := range pkg.Interfaces[0].Methods {
+		var in, out []string
+		for _, param := range method.In {
+			in = append(in, param.Name)
+		}
+		for _, result := range method.Out {
+			out = append(out, result.Name)
+		}
+
+		if !slices.Equal(in, want[method.Name][0]) || !slices.Equal(out, want[method.Name][1]) {
+			t.Errorf("Execute() %s names = %v %v, want %v", method.Name, in, out, want[method.Name])
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	"golang.org/x/tools/go/packages"
+)
+
+// generateDirective annotates interfaces generated by ScanCommand, followed by name=value
+// arguments, e.g. `//implgen:generate dst=../impl name=Service kinds=stub,funcs`
+const generateDirective = "//implgen:generate"
+
+// Annotation is an interface annotated with generateDirective
+type Annotation struct {
+	// Src is the absolute path of the file declaring the interface
+	Src string
+	// Interface is the annotated interface name
+	Interface string
+	// Args are the directive name=value arguments
+	Args []string
+	// Package is the parsed file, shared by annotations of the same file
+	Package model.Package
+}
+
+// ScanCommand loads packages matching patterns at once and parses the annotated interfaces,
+// load errors fail the scan only for packages declaring annotated interfaces
+type ScanCommand struct {
+	ctx      context.Context
+	dir      string
+	patterns []string
+}
+
+func NewScanCommand(ctx context.Context, dir string, patterns []string) *ScanCommand {
+	return &ScanCommand{
+		ctx:      ctx,
+		dir:      dir,
+		patterns: patterns,
+	}
+}
+
+func (cmd *ScanCommand) Execute() ([]Annotation, error) {
+	cfg := &packages.Config{
+		Context: cmd.ctx,
+		Mode:    loadMode,
+		Dir:     cmd.dir,
+	}
+
+	pkgs, err := packages.Load(cfg, cmd.patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	var annotations []Annotation
+	var errs []error
+
+	for _, pkg := range pkgs {
+		annotated := make(map[*ast.File][]Annotation)
+		for _, astFile := range pkg.Syntax {
+			if fileAnnotations := scanFile(astFile); len(fileAnnotations) > 0 {
+				annotated[astFile] = fileAnnotations
+			}
+		}
+		if len(annotated) == 0 {
+			// load errors of packages without directives don't fail the scan, e.g. generated
+			// implementations missing methods just added to their interfaces are regenerated
+			continue
+		}
+
+		if err := loadErrors(pkg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", pkg.PkgPath, err))
+			continue
+		}
+
+		for _, astFile := range pkg.Syntax {
+			fileAnnotations := annotated[astFile]
+			if len(fileAnnotations) == 0 {
+				continue
+			}
+
+			src := pkg.Fset.File(astFile.Pos()).Name()
+
+			_package, err := NewCommand(src).parseFile(pkg, astFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", src, err)
+			}
+
+			for _, annotation := range fileAnnotations {
+				annotation.Src = src
+				annotation.Package = _package
+				annotations = append(annotations, annotation)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return annotations, nil
+}
+
+// scanFile returns the interfaces of the file annotated with generateDirective,
+// an interface annotated several times is generated for every directive
+func scanFile(astFile *ast.File) []Annotation {
+	var annotations []Annotation
+
+	for _, decl := range astFile.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			tspec := spec.(*ast.TypeSpec)
+			if _, ok := tspec.Type.(*ast.InterfaceType); !ok {
+				continue
+			}
+
+			doc := typeDoc(gen, tspec)
+			if doc == nil {
+				continue
+			}
+
+			for _, comment := range doc.List {
+				args, ok := strings.CutPrefix(comment.Text, generateDirective)
+				if !ok || args != "" && args[0] != ' ' && args[0] != '\t' {
+					continue
+				}
+
+				annotations = append(annotations, Annotation{Interface: tspec.Name.Name, Args: strings.Fields(args)})
+			}
+		}
+	}
+
+	return annotations
+}
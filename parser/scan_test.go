@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files keyed by slash-separated paths relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScanCommand(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n",
+		"ports/user.go": `package ports
+
+// UserRepo stores users.
+//
+//implgen:generate dst=../impl kinds=stub,funcs
+//implgen:generate dst=../mock name=Mock
+type UserRepo interface {
+	Get(id int) (string, error)
+}
+
+// OrderRepo isn't annotated.
+type OrderRepo interface {
+	Get(id int) (string, error)
+}
+
+type (
+	//implgen:generate dst=../impl
+	Cache interface {
+		Get(key string) string
+	}
+
+	//implgen:generatex dst=../impl
+	Queue interface {
+		Push(item string)
+	}
+)
+`,
+		"ports/empty.go": "package ports\n",
+	}
+	writeFiles(t, dir, files)
+
+	annotations, err := NewScanCommand(context.Background(), dir, []string{"./..."}).Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	src := filepath.Join(dir, "ports", "user.go")
+	want := []Annotation{
+		{Src: src, Interface: "UserRepo", Args: []string{"dst=../impl", "kinds=stub,funcs"}},
+		{Src: src, Interface: "UserRepo", Args: []string{"dst=../mock", "name=Mock"}},
+		{Src: src, Interface: "Cache", Args: []string{"dst=../impl"}},
+	}
+	if len(annotations) != len(want) {
+		t.Fatalf("Execute() = %d annotations, want %d", len(annotations), len(want))
+	}
+	for i, annotation := range annotations {
+		if annotation.Src != want[i].Src || annotation.Interface != want[i].Interface ||
+			!slices.Equal(annotation.Args, want[i].Args) {
+			t.Errorf("Execute() annotation %d = %s %s %v, want %s %s %v", i,
+				annotation.Src, annotation.Interface, annotation.Args,
+				want[i].Src, want[i].Interface, want[i].Args)
+		}
+		if annotation.Package.Name != "ports" || len(annotation.Package.Interfaces) != 4 {
+			t.Errorf("Execute() annotation %d package = %+v, want the parsed ports file", i, annotation.Package)
+		}
+	}
+}
+
+func TestScanCommandLoadErrors(t *testing.T) {
+	const store = `package svc
+
+//implgen:generate dst=../impl
+type Store interface {
+	Load(key string) string
+	Purge()
+}
+`
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "implementation missing a new method",
+			files: map[string]string{
+				"svc/store.go": store,
+				"impl/store.go": `package impl
+
+import "example.com/app/svc"
+
+type Store struct{}
+
+var _ svc.Store = (*Store)(nil)
+
+func (s *Store) Load(key string) string { return key }
+`,
+			},
+		},
+		{
+			name: "annotated package",
+			files: map[string]string{
+				"svc/store.go": store,
+				"svc/broken.go": `package svc
+
+var broken int = "broken"
+`,
+			},
+			wantErr: "example.com/app/svc: package load errors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"go.mod": "module example.com/app\n"})
+			writeFiles(t, dir, tt.files)
+
+			annotations, err := NewScanCommand(context.Background(), dir, []string{"./..."}).Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Execute() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if len(annotations) != 1 || annotations[0].Interface != "Store" {
+				t.Errorf("Execute() = %+v, want the Store annotation", annotations)
+			}
+		})
+	}
+}
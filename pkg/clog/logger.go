@@ -8,35 +8,50 @@ import (
 )
 
 func Fatal(msg ...interface{}) {
-	fmt.Println(append([]interface{}{color.RedString("Fatal error:")}, msg...)...)
+	fmt.Fprintln(os.Stderr, append([]interface{}{color.RedString("Fatal error:")}, msg...)...)
 	os.Exit(1)
 }
 
 func Error(msg ...interface{}) {
-	fmt.Println(append([]interface{}{color.RedString("Error:")}, msg...)...)
+	fmt.Fprintln(os.Stderr, append([]interface{}{color.RedString("Error:")}, msg...)...)
 }
 
 func Warn(msg ...interface{}) {
-	fmt.Println(append([]interface{}{color.YellowString("Warning:")}, msg...)...)
+	fmt.Fprintln(os.Stderr, append([]interface{}{color.YellowString("Warning:")}, msg...)...)
 }
 
 func Info(msg ...interface{}) {
-	fmt.Println(append([]interface{}{color.GreenString("Info:")}, msg...)...)
+	fmt.Fprintln(os.Stderr, append([]interface{}{color.GreenString("Info:")}, msg...)...)
 }
 
 func Fatalf(f string, v ...interface{}) {
-	fmt.Printf(color.RedString("Fatal error: ")+f+"\n", v...)
+	fmt.Fprintf(os.Stderr, color.RedString("Fatal error: ")+f+"\n", v...)
 	os.Exit(1)
 }
 
 func Errorf(f string, v ...interface{}) {
-	fmt.Printf(color.RedString("Error: ")+f+"\n", v...)
+	fmt.Fprintf(os.Stderr, color.RedString("Error: ")+f+"\n", v...)
 }
 
 func Warnf(f string, v ...interface{}) {
-	fmt.Printf(color.YellowString("Warning: ")+f+"\n", v...)
+	fmt.Fprintf(os.Stderr, color.YellowString("Warning: ")+f+"\n", v...)
 }
 
 func Infof(f string, v ...interface{}) {
-	fmt.Printf(color.GreenString("Info: ")+f+"\n", v...)
+	fmt.Fprintf(os.Stderr, color.GreenString("Info: ")+f+"\n", v...)
+}
+
+// Logger implements printf-style logging interfaces with the package functions
+type Logger struct{}
+
+func (Logger) Infof(f string, v ...interface{}) {
+	Infof(f, v...)
+}
+
+func (Logger) Warnf(f string, v ...interface{}) {
+	Warnf(f, v...)
+}
+
+func (Logger) Errorf(f string, v ...interface{}) {
+	Errorf(f, v...)
 }
@@ -0,0 +1,332 @@
+// Package implgen is the library behind the implgen CLI: it parses source interfaces,
+// generates their implementations and writes them, either at once with Generate
+// or step by step with Scan, Parse, GenerateFiles and Write.
+package implgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/not-for-prod/implgen/config"
+	"github.com/not-for-prod/implgen/generator"
+	"github.com/not-for-prod/implgen/model"
+	"github.com/not-for-prod/implgen/parser"
+	"github.com/not-for-prod/implgen/writer"
+)
+
+const (
+	// DefaultImplementationName is the implementation struct name of targets without impl-name
+	DefaultImplementationName = "Implementation"
+)
+
+// DefaultKinds are generated for targets without kinds
+var DefaultKinds = []string{generator.KindStub}
+
+// Logger reports the written files, e.g. clog.Logger
+type Logger interface {
+	Infof(f string, v ...interface{})
+}
+
+// Options configures Generate
+type Options struct {
+	// Targets are generated in the given order, see config.Target
+	Targets []config.Target
+
+	// Packages are the parsed sources of Targets keyed by config.Target.Src and GRPCSrc,
+	// the missing ones are parsed by Generate at once.
+	Packages map[string]model.Package
+
+	// Jobs is the number of targets generated and formatted concurrently, GOMAXPROCS when it isn't positive.
+	Jobs int
+
+	// Verbose makes the writer prompt for existing files unless a target sets its overwrite policy.
+	Verbose bool
+
+	// FS is the file system files are written to, writer.OS when it is nil.
+	FS writer.FS
+
+	// Logger reports the written files, nothing is reported when it is nil.
+	Logger Logger
+}
+
+// Result is the outcome of Generate
+type Result struct {
+	// Files are the files of the targets generated and written without errors, in the order of targets.
+	Files []model.File
+
+	// Written are paths of the files written, existing ones are kept according to the overwrite policy.
+	Written []string
+}
+
+// generation is the outcome of generating a single target
+type generation struct {
+	files []model.File
+	err   error
+}
+
+// Generate validates, parses and generates targets in a pool of workers, then writes their files
+// in the order of targets, so the output doesn't depend on scheduling. Errors of all targets,
+// including their source parse errors, are joined in the same order, files of the succeeded
+// ones are written and returned anyway
+func Generate(ctx context.Context, opts Options) (Result, error) {
+	if err := Validate(opts.Targets); err != nil {
+		return Result{}, fmt.Errorf("invalid targets: %w", err)
+	}
+
+	packages, parseErrs, err := parseMissing(ctx, opts.Targets, opts.Packages)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to parse source files: %w", err)
+	}
+
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+
+	generations := make([]generation, len(opts.Targets))
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+
+	for range min(jobs, len(opts.Targets)) {
+		wg.Go(func() {
+			for i := range indices {
+				target := opts.Targets[i]
+				if err := ctx.Err(); err != nil {
+					generations[i] = generation{err: err}
+					continue
+				}
+				if err := parseErrs[target.Src]; err != nil {
+					generations[i] = generation{err: fmt.Errorf("failed to parse source file: %w", err)}
+					continue
+				}
+				if err := parseErrs[target.GRPCSrc]; err != nil {
+					generations[i] = generation{err: fmt.Errorf("failed to parse grpc source file: %w", err)}
+					continue
+				}
+
+				grpcServer := generator.GRPCServer{Package: packages[target.GRPCSrc], Name: target.GRPCServer}
+				files, err := generateFiles(target, packages[target.Src], grpcServer)
+				generations[i] = generation{files: files, err: err}
+			}
+		})
+	}
+
+	for i := range opts.Targets {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	var result Result
+	var errs []error
+
+	for i, target := range opts.Targets {
+		err := generations[i].err
+		if err == nil {
+			var written []string
+
+			written, err = Write(target, generations[i].files, opts.Verbose, opts.FS)
+			if err == nil {
+				result.Files = append(result.Files, generations[i].files...)
+			}
+			result.Written = append(result.Written, written...)
+
+			if opts.Logger != nil {
+				for _, path := range written {
+					opts.Logger.Infof("written %s", path)
+				}
+			}
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", targetName(target), err))
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// Validate joins validation errors of all targets
+func Validate(targets []config.Target) error {
+	var errs []error
+
+	for i, target := range targets {
+		if err := target.Validate(); err != nil {
+			name := targetName(target)
+			if name == "" {
+				name = fmt.Sprintf("target %d", i+1)
+			}
+
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Scan returns targets of the interfaces annotated with //implgen:generate in packages
+// matching patterns relative to dir, along with their parsed packages
+func Scan(ctx context.Context, dir string, patterns []string) ([]config.Target, map[string]model.Package, error) {
+	annotations, err := parser.NewScanCommand(ctx, dir, patterns).Execute()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targets := make([]config.Target, 0, len(annotations))
+	packages := make(map[string]model.Package, len(annotations))
+	var errs []error
+
+	for _, annotation := range annotations {
+		target, err := config.FromDirective(annotation.Src, annotation.Interface, annotation.Args)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s directive: %w", annotation.Src, annotation.Interface, err))
+			continue
+		}
+
+		targets = append(targets, target)
+		packages[annotation.Src] = annotation.Package
+	}
+
+	return targets, packages, errors.Join(errs...)
+}
+
+// Parse parses source files loading their packages at once, see parser.BatchCommand
+func Parse(ctx context.Context, srcs []string) (map[string]model.Package, error) {
+	return parser.NewBatchCommand(ctx, srcs).Execute()
+}
+
+// GenerateFiles generates the target implementation from its parsed source package and formats it,
+// DefaultImplementationName and DefaultKinds are used when the target doesn't set them
+func GenerateFiles(target config.Target, _package model.Package) ([]model.File, error) {
+	grpcServer, err := targetToGRPCServer(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return generateFiles(target, _package, grpcServer)
+}
+
+// generateFiles is GenerateFiles with the gRPC server of the target already resolved
+func generateFiles(target config.Target, _package model.Package, grpcServer generator.GRPCServer) ([]model.File, error) {
+	target = config.Target{ImplementationName: DefaultImplementationName, Kinds: DefaultKinds}.Merge(target)
+
+	generateCommand, err := targetToGenerateCommand(target, grpcServer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run code generation using provided options
+	files, err := generateCommand.Execute(_package)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate implementation: %w", err)
+	}
+
+	for i, file := range files {
+		files[i].Data, err = writer.Format(file.Path, file.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format %s: %w", file.Path, err)
+		}
+	}
+
+	return files, nil
+}
+
+// Write writes the files generated by GenerateFiles into fsys according to the target overwrite policy,
+// returning paths of the written ones
+func Write(target config.Target, files []model.File, verbose bool, fsys writer.FS) ([]string, error) {
+	written, err := writer.NewCommand(verbose, target.Overwrite, fsys).Write(files)
+	if err != nil {
+		return written, fmt.Errorf("failed to write basic interfaces implementation: %w", err)
+	}
+
+	return written, nil
+}
+
+// parseMissing returns packages extended with the parsed sources and gRPC sources of targets
+// missing in it, along with parse errors of the failed sources keyed by their paths.
+// Every source is parsed once however many targets share it
+func parseMissing(
+	ctx context.Context,
+	targets []config.Target,
+	packages map[string]model.Package,
+) (map[string]model.Package, map[string]error, error) {
+	var srcs []string
+	for _, target := range targets {
+		for _, src := range []string{target.Src, target.GRPCSrc} {
+			if _, ok := packages[src]; !ok && src != "" && !slices.Contains(srcs, src) {
+				srcs = append(srcs, src)
+			}
+		}
+	}
+
+	if len(srcs) == 0 {
+		return packages, nil, nil
+	}
+
+	parsed, errs, err := parser.NewBatchCommand(ctx, srcs).ExecuteEach()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for src, _package := range packages {
+		parsed[src] = _package
+	}
+
+	return parsed, errs, nil
+}
+
+// targetName returns the name errors of the target are reported with
+func targetName(target config.Target) string {
+	if target.InterfaceName == "" {
+		return target.Src
+	}
+
+	return target.Src + " " + target.InterfaceName
+}
+
+// targetToGenerateCommand - convert config.Target into generator.Command
+func targetToGenerateCommand(target config.Target, grpcServer generator.GRPCServer) (*generator.Command, error) {
+	deps := make([]model.Dependency, 0, len(target.Deps))
+	for _, spec := range target.Deps {
+		dep, err := model.ParseDependency(spec)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+
+	return generator.NewCommand(
+		target.Dst,
+		target.InterfaceName,             // src interface name
+		target.ImplementationName,        // dst struct name
+		target.ImplementationPackageName, // dst package name
+		target.SingleFile != nil && *target.SingleFile,
+		target.Kinds,
+		target.MultiSelect,
+		grpcServer,
+		deps,
+		target.DepNilCheck != nil && *target.DepNilCheck,
+		target.Constructor,
+	), nil
+}
+
+// targetToGRPCServer - parse the protoc-generated service set by config.Target into generator.GRPCServer
+func targetToGRPCServer(target config.Target) (generator.GRPCServer, error) {
+	if target.GRPCSrc == "" {
+		return generator.GRPCServer{Name: target.GRPCServer}, nil
+	}
+
+	_package, err := parser.NewCommand(target.GRPCSrc).Execute()
+	if err != nil {
+		return generator.GRPCServer{}, fmt.Errorf("failed to parse grpc source file: %w", err)
+	}
+
+	return generator.GRPCServer{
+		Package: _package,
+		Name:    target.GRPCServer,
+	}, nil
+}
@@ -0,0 +1,157 @@
+package implgen_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/config"
+	"github.com/not-for-prod/implgen/pkg/implgen"
+	"github.com/not-for-prod/implgen/writer"
+)
+
+func TestGenerateParseErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.go")
+	fs := writer.NewMemory()
+
+	result, err := implgen.Generate(context.Background(), implgen.Options{
+		Targets: []config.Target{
+			{Src: missing, InterfaceName: "Missing", Dst: "out"},
+			{Src: "../../example/in/interface.go", InterfaceName: "TestInterface", Dst: "out"},
+		},
+		FS: fs,
+	})
+
+	if err == nil || !strings.Contains(err.Error(), missing+" Missing: failed to parse source file") {
+		t.Errorf("Generate() error = %v, want the parse error of %s", err, missing)
+	}
+	if err != nil && strings.Contains(err.Error(), "TestInterface") {
+		t.Errorf("Generate() error = %v, want no error of TestInterface", err)
+	}
+
+	if len(result.Written) == 0 || len(result.Written) != len(fs.Files()) {
+		t.Errorf("Generate() written = %v, want the TestInterface files %v", result.Written, fs.Files())
+	}
+	for _, path := range result.Written {
+		if !strings.HasPrefix(path, filepath.Join("out", "test-interface")) {
+			t.Errorf("Generate() written %s, want TestInterface files only", path)
+		}
+	}
+}
+
+func TestGenerateGRPCSources(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"go.mod": "module example.com/app\n",
+		"greeter/greeter_grpc.pb.go": `package greeter
+
+import "context"
+
+type HelloRequest struct{ Name string }
+
+type HelloReply struct{ Message string }
+
+type GreeterServer interface {
+	SayHello(context.Context, *HelloRequest) (*HelloReply, error)
+	mustEmbedUnimplementedGreeterServer()
+}
+
+type UnimplementedGreeterServer struct{}
+`,
+		"svc/svc.go": `package svc
+
+import (
+	"context"
+
+	"example.com/app/greeter"
+)
+
+type Greeter interface {
+	SayHello(ctx context.Context, req *greeter.HelloRequest) (*greeter.HelloReply, error)
+}
+
+type Welcomer interface {
+	SayHello(ctx context.Context, req *greeter.HelloRequest) (*greeter.HelloReply, error)
+}
+`,
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src := filepath.Join(dir, "svc", "svc.go")
+	grpcSrc := filepath.Join(dir, "greeter", "greeter_grpc.pb.go")
+	missing := filepath.Join(dir, "missing", "missing_grpc.pb.go")
+
+	// the targets share the grpc source, which is parsed once along with the source
+	result, err := implgen.Generate(context.Background(), implgen.Options{
+		Targets: []config.Target{
+			{Src: src, InterfaceName: "Greeter", Dst: "out", Kinds: []string{"grpc"}, GRPCSrc: grpcSrc},
+			{Src: src, InterfaceName: "Welcomer", Dst: "out", Kinds: []string{"grpc"}, GRPCSrc: grpcSrc},
+			{Src: src, InterfaceName: "Greeter", Dst: "broken", Kinds: []string{"grpc"}, GRPCSrc: missing},
+		},
+		FS: writer.NewMemory(),
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "failed to parse grpc source file") {
+		t.Errorf("Generate() error = %v, want the grpc source parse error of %s", err, missing)
+	}
+	if err != nil && strings.Contains(err.Error(), "Welcomer") {
+		t.Errorf("Generate() error = %v, want no error of Welcomer", err)
+	}
+
+	for _, folder := range []string{"greeter", "welcomer"} {
+		if !slices.ContainsFunc(result.Written, func(path string) bool {
+			return strings.HasPrefix(path, filepath.Join("out", folder)+string(filepath.Separator))
+		}) {
+			t.Errorf("Generate() written = %v, want the %s files", result.Written, folder)
+		}
+	}
+}
+
+// failingFS fails writes of the files within dir
+type failingFS struct {
+	*writer.Memory
+	dir string
+}
+
+func (f failingFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if strings.HasPrefix(name, f.dir) {
+		return errors.New("disk full")
+	}
+
+	return f.Memory.WriteFile(name, data, perm)
+}
+
+func TestGenerateWriteErrors(t *testing.T) {
+	result, err := implgen.Generate(context.Background(), implgen.Options{
+		Targets: []config.Target{
+			{Src: "../../example/in/interface.go", InterfaceName: "TestInterface", Dst: "broken"},
+			{Src: "../../example/in/interface.go", InterfaceName: "TestInterface", Dst: "out"},
+		},
+		FS: failingFS{Memory: writer.NewMemory(), dir: "broken"},
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Generate() error = %v, want the write error", err)
+	}
+
+	if len(result.Files) == 0 {
+		t.Error("Generate() files are empty, want the files of the written target")
+	}
+	for _, file := range result.Files {
+		if !strings.HasPrefix(file.Path, "out") {
+			t.Errorf("Generate() files hold %s of the target failed to write", file.Path)
+		}
+	}
+}
@@ -38,3 +38,23 @@ func KebabCase(str string) string {
 
 	return string(result)
 }
+
+// PascalCase converts camelCase, snake_case or kebab-case to PascalCase
+func PascalCase(str string) string {
+	var result []rune
+	upper := true
+
+	for _, c := range str {
+		if c == '_' || c == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			c = []rune(strings.ToUpper(string(c)))[0]
+			upper = false
+		}
+		result = append(result, c)
+	}
+
+	return string(result)
+}
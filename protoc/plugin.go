@@ -0,0 +1,240 @@
+package protoc
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/not-for-prod/implgen/generator"
+	"github.com/not-for-prod/implgen/model"
+	"github.com/not-for-prod/implgen/writer"
+	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const (
+	// Plugin parameter names, they match the implgen CLI flags
+	dstParam                       = "dst"
+	interfaceNameParam             = "interface-name"
+	implementationNameParam        = "impl-name"
+	implementationPackageNameParam = "impl-package"
+	singleFileParam                = "single-file"
+	kindParam                      = "kind"
+	multiSelectParam               = "multi-select"
+	depParam                       = "dep"
+	depNilCheckParam               = "dep-nil-check"
+	constructorParam               = "constructor"
+)
+
+// Command runs implgen as a protoc plugin: it generates implementations
+// of the protoc-generated XxxServer interface for every service of the proto files.
+type Command struct {
+	// params holds the plugin parameters passed via `--implgen_opt=name=value,...`.
+	params *pflag.FlagSet
+}
+
+// NewCommand creates a new Command with the plugin parameters registered.
+func NewCommand() *Command {
+	params := pflag.NewFlagSet("protoc-gen-implgen", pflag.ContinueOnError)
+
+	params.String(dstParam, "", "destination dir path relative to the plugin output dir")
+	params.String(interfaceNameParam, "", "server interface name, e.g. GreeterServer")
+	params.String(implementationNameParam, "Implementation", "generated implementation struct name")
+	params.String(
+		implementationPackageNameParam, "",
+		"generated implementation package name, can be used only when interface name is set",
+	)
+	params.Bool(singleFileParam, false, "generate interface methods into single file")
+	params.StringSlice(kindParam, []string{generator.KindStub}, "kinds of generated output, repeat to set several")
+	params.String(multiSelectParam, "", "delegate results returned by multi methods: first, last")
+	params.StringArray(depParam, nil, "implementation dependency as name=type, repeat to set several")
+	params.Bool(depNilCheckParam, false, "make the implementation constructor return an error on nil dependencies")
+	params.String(constructorParam, generator.ConstructorPositional, "implementation constructor style: positional, options")
+
+	return &Command{params: params}
+}
+
+// Execute reads a CodeGeneratorRequest from r and writes the CodeGeneratorResponse into w.
+func (cmd *Command) Execute(r io.Reader, w io.Writer) error {
+	in, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+
+	req := &pluginpb.CodeGeneratorRequest{}
+	if err = proto.Unmarshal(in, req); err != nil {
+		return fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	plugin, err := protogen.Options{ParamFunc: cmd.params.Set}.New(req)
+	if err != nil {
+		return err
+	}
+	// generated code doesn't depend on message fields, protoc rejects optional ones otherwise
+	plugin.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+
+	if err = cmd.generate(plugin); err != nil {
+		plugin.Error(err)
+	}
+
+	out, err := proto.Marshal(plugin.Response())
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+// generate writes implementations for the services of all files requested for generation.
+func (cmd *Command) generate(plugin *protogen.Plugin) error {
+	generateCommand, err := cmd.generateCommand()
+	if err != nil {
+		return err
+	}
+
+	for _, file := range plugin.Files {
+		if !file.Generate || len(file.Services) == 0 {
+			continue
+		}
+
+		files, err := generateCommand.Execute(filePackage(plugin, file))
+		if err != nil {
+			return fmt.Errorf("failed to generate implementation for %s: %w", file.Desc.Path(), err)
+		}
+
+		for _, f := range files {
+			data, err := writer.Format(f.Path, f.Data)
+			if err != nil {
+				return fmt.Errorf("failed to format %s: %w", f.Path, err)
+			}
+
+			g := plugin.NewGeneratedFile(f.Path, "")
+			if _, err = g.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// generateCommand builds generator.Command from the plugin parameters.
+func (cmd *Command) generateCommand() (*generator.Command, error) {
+	dst, _ := cmd.params.GetString(dstParam)
+	interfaceName, _ := cmd.params.GetString(interfaceNameParam)
+	implementationName, _ := cmd.params.GetString(implementationNameParam)
+	implementationPackageName, _ := cmd.params.GetString(implementationPackageNameParam)
+	singleFile, _ := cmd.params.GetBool(singleFileParam)
+	kinds, _ := cmd.params.GetStringSlice(kindParam)
+	multiSelect, _ := cmd.params.GetString(multiSelectParam)
+	depSpecs, _ := cmd.params.GetStringArray(depParam)
+	depNilCheck, _ := cmd.params.GetBool(depNilCheckParam)
+	constructor, _ := cmd.params.GetString(constructorParam)
+
+	// Validate: impl package name requires interface name
+	if implementationPackageName != "" && interfaceName == "" {
+		return nil, fmt.Errorf(
+			"parameter %q requires %q to be set",
+			implementationPackageNameParam,
+			interfaceNameParam,
+		)
+	}
+
+	deps := make([]model.Dependency, 0, len(depSpecs))
+	for _, spec := range depSpecs {
+		dep, err := model.ParseDependency(spec)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+
+	return generator.NewCommand(
+		dst,
+		interfaceName,             // src interface name
+		implementationName,        // dst struct name
+		implementationPackageName, // dst package name
+		singleFile,
+		kinds,
+		multiSelect,
+		generator.GRPCServer{},
+		deps,
+		depNilCheck,
+		constructor,
+	), nil
+}
+
+// filePackage converts services of the proto file into the protoc-gen-go-grpc
+// XxxServer interfaces the way parser.Command does for Go source files.
+func filePackage(plugin *protogen.Plugin, file *protogen.File) model.Package {
+	imports := []model.Import{
+		{Alias: string(file.GoPackageName), Path: string(file.GoImportPath)},
+		{Alias: "context", Path: "context"},
+		{Alias: "grpc", Path: "google.golang.org/grpc"},
+	}
+	aliases := map[protogen.GoImportPath]string{file.GoImportPath: string(file.GoPackageName)}
+
+	// qualify returns the type name qualified with its package alias, importing it if needed.
+	qualify := func(ident protogen.GoIdent) string {
+		alias, ok := aliases[ident.GoImportPath]
+		if !ok {
+			alias = path.Base(string(ident.GoImportPath))
+			for _, f := range plugin.Files {
+				if f.GoImportPath == ident.GoImportPath {
+					alias = string(f.GoPackageName)
+				}
+			}
+
+			aliases[ident.GoImportPath] = alias
+			imports = append(imports, model.Import{Alias: alias, Path: string(ident.GoImportPath)})
+		}
+
+		return alias + "." + ident.GoName
+	}
+
+	interfaces := make([]model.Interface, 0, len(file.Services))
+	for _, service := range file.Services {
+		ifce := model.Interface{
+			Name:   service.GoName + "Server",
+			Embeds: []string{string(file.GoPackageName) + ".Unimplemented" + service.GoName + "Server"},
+		}
+
+		for _, method := range service.Methods {
+			stream := model.Parameter{
+				Name: "stream",
+				Type: string(file.GoPackageName) + "." + service.GoName + "_" + method.GoName + "Server",
+			}
+			req := model.Parameter{Name: "req", Type: "*" + qualify(method.Input.GoIdent)}
+			errResult := model.Parameter{Name: "err", Type: "error"}
+
+			m := model.Method{Name: method.GoName}
+			switch {
+			case method.Desc.IsStreamingClient():
+				m.In = []model.Parameter{stream}
+				m.Out = []model.Parameter{errResult}
+			case method.Desc.IsStreamingServer():
+				m.In = []model.Parameter{req, stream}
+				m.Out = []model.Parameter{errResult}
+			default:
+				m.In = []model.Parameter{{Name: "ctx", Type: "context.Context"}, req}
+				m.Out = []model.Parameter{
+					{Name: "res", Type: "*" + qualify(method.Output.GoIdent)},
+					errResult,
+				}
+			}
+
+			ifce.Methods = append(ifce.Methods, m)
+		}
+
+		interfaces = append(interfaces, ifce)
+	}
+
+	return model.Package{
+		Name:       string(file.GoPackageName),
+		Interfaces: interfaces,
+		Imports:    imports,
+	}
+}
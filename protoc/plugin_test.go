@@ -0,0 +1,209 @@
+package protoc_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"testing"
+
+	"github.com/not-for-prod/implgen/protoc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// greeterPath is the Go import path of the greeter.proto package
+const greeterPath = "example.com/greeter"
+
+// greeterSource declares what protoc-gen-go and protoc-gen-go-grpc generate for greeter.proto
+// and the implementation uses
+const greeterSource = `package greeter
+
+import "context"
+
+type HelloRequest struct{ Name string }
+
+type HelloReply struct{ Message string }
+
+type GreeterServer interface {
+	SayHello(context.Context, *HelloRequest) (*HelloReply, error)
+	SayHellos(*HelloRequest, Greeter_SayHellosServer) error
+	mustEmbedUnimplementedGreeterServer()
+}
+
+type UnimplementedGreeterServer struct{}
+
+func (UnimplementedGreeterServer) SayHello(context.Context, *HelloRequest) (*HelloReply, error) {
+	return nil, nil
+}
+
+func (UnimplementedGreeterServer) SayHellos(*HelloRequest, Greeter_SayHellosServer) error {
+	return nil
+}
+
+func (UnimplementedGreeterServer) mustEmbedUnimplementedGreeterServer() {}
+
+type Greeter_SayHellosServer interface {
+	Send(*HelloReply) error
+	Context() context.Context
+}
+`
+
+// greeterRequest returns the request protoc sends for greeter.proto with a unary
+// and a server-streaming method
+func greeterRequest(parameter string) *pluginpb.CodeGeneratorRequest {
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"greeter.proto"},
+		Parameter:      proto.String(parameter),
+		ProtoFile: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("greeter.proto"),
+			Package: proto.String("greeter"),
+			Syntax:  proto.String("proto3"),
+			Options: &descriptorpb.FileOptions{GoPackage: proto.String(greeterPath + ";greeter")},
+			MessageType: []*descriptorpb.DescriptorProto{
+				{Name: proto.String("HelloRequest")},
+				{Name: proto.String("HelloReply")},
+			},
+			Service: []*descriptorpb.ServiceDescriptorProto{{
+				Name: proto.String("Greeter"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("SayHello"),
+						InputType:  proto.String(".greeter.HelloRequest"),
+						OutputType: proto.String(".greeter.HelloReply"),
+					},
+					{
+						Name:            proto.String("SayHellos"),
+						InputType:       proto.String(".greeter.HelloRequest"),
+						OutputType:      proto.String(".greeter.HelloReply"),
+						ServerStreaming: proto.Bool(true),
+					},
+				},
+			}},
+		}},
+	}
+}
+
+// execute runs the plugin with req and returns its response
+func execute(t *testing.T, req *pluginpb.CodeGeneratorRequest) *pluginpb.CodeGeneratorResponse {
+	t.Helper()
+
+	in, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err = protoc.NewCommand().Execute(bytes.NewReader(in), &out); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	res := &pluginpb.CodeGeneratorResponse{}
+	if err = proto.Unmarshal(out.Bytes(), res); err != nil {
+		t.Fatal(err)
+	}
+
+	return res
+}
+
+// greeterImporter imports the greeter package from greeterSource and others from their sources
+type greeterImporter struct {
+	fset    *token.FileSet
+	greeter *types.Package
+	source  types.Importer
+}
+
+func (i *greeterImporter) Import(path string) (*types.Package, error) {
+	if path != greeterPath {
+		return i.source.Import(path)
+	}
+	if i.greeter != nil {
+		return i.greeter, nil
+	}
+
+	file, err := parser.ParseFile(i.fset, "greeter.pb.go", greeterSource, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	i.greeter, err = (&types.Config{Importer: i.source}).Check(greeterPath, i.fset, []*ast.File{file}, nil)
+
+	return i.greeter, err
+}
+
+func TestPlugin(t *testing.T) {
+	res := execute(t, greeterRequest("dst=impl"))
+	if res.Error != nil {
+		t.Fatalf("Execute() response error = %s", res.GetError())
+	}
+
+	names := make([]string, 0, len(res.File))
+	for _, file := range res.File {
+		names = append(names, file.GetName())
+	}
+	slices.Sort(names)
+
+	want := []string{
+		"impl/greeter-server/implementation.go",
+		"impl/greeter-server/say_hello.go",
+		"impl/greeter-server/say_hellos.go",
+	}
+	if !slices.Equal(names, want) {
+		t.Fatalf("generated files = %v, want %v", names, want)
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(res.File))
+	for _, file := range res.File {
+		f, err := parser.ParseFile(fset, file.GetName(), file.GetContent(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+
+	conf := types.Config{Importer: &greeterImporter{fset: fset, source: importer.ForCompiler(fset, "source", nil)}}
+	if _, err := conf.Check("example.com/impl/greeter-server", fset, files, nil); err != nil {
+		t.Errorf("generated code doesn't compile: %v", err)
+	}
+}
+
+func TestPluginParams(t *testing.T) {
+	// impl-package requires interface-name, the error is reported in the response
+	res := execute(t, greeterRequest("dst=impl,impl-package=greeter"))
+	if res.GetError() == "" {
+		t.Errorf("Execute() response error is empty, want the impl-package parameter error")
+	}
+}
+
+func TestPluginProto3Optional(t *testing.T) {
+	req := greeterRequest("dst=impl")
+	req.ProtoFile[0].MessageType[0] = &descriptorpb.DescriptorProto{
+		Name: proto.String("HelloRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{{
+			Name:           proto.String("name"),
+			JsonName:       proto.String("name"),
+			Number:         proto.Int32(1),
+			Label:          descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:           descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			OneofIndex:     proto.Int32(0),
+			Proto3Optional: proto.Bool(true),
+		}},
+		OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("_name")}},
+	}
+
+	res := execute(t, req)
+	if res.Error != nil {
+		t.Fatalf("Execute() response error = %s", res.GetError())
+	}
+	if res.GetSupportedFeatures()&uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL) == 0 {
+		t.Errorf("Execute() supported features = %b, want proto3 optional", res.GetSupportedFeatures())
+	}
+	if len(res.File) == 0 {
+		t.Error("Execute() generated no files for a service with an optional field")
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/not-for-prod/implgen/config"
+	"github.com/not-for-prod/implgen/pkg/clog"
+	"github.com/not-for-prod/implgen/pkg/implgen"
+	"github.com/not-for-prod/implgen/writer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// Flag names used by the watch command
+	intervalFlag = "interval"
+	debounceFlag = "debounce"
+)
+
+// newWatchCommand creates the command regenerating targets whenever their sources change
+func newWatchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch [packages]",
+		Short: "regenerates implementations when source interfaces change",
+		Long: `This command polls Go files in the directories of the target sources and regenerates
+the targets in sync mode when they change: missing methods and declarations are appended
+to the generated files, existing ones including hand-written bodies are kept as is.
+Targets are resolved like by implgen itself.`,
+		Args: cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			flags := cmd.Flags()
+			interval, _ := flags.GetDuration(intervalFlag)
+			debounce, _ := flags.GetDuration(debounceFlag)
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			watch(ctx, flags, args, interval, debounce)
+		},
+	}
+
+	cmd.Flags().Duration(intervalFlag, 500*time.Millisecond, "interval of polling source files for changes")
+	cmd.Flags().Duration(debounceFlag, 300*time.Millisecond, "quiet period awaited after changes before regenerating")
+
+	return cmd
+}
+
+// watch regenerates targets resolved from flags and patterns until ctx is done,
+// changes made within debounce of each other are regenerated at once
+func watch(ctx context.Context, flags *pflag.FlagSet, patterns []string, interval, debounce time.Duration) {
+	dirs, err := regenerate(ctx, flags, patterns)
+	exitOnErr("failed to generate", err)
+
+	clog.Infof("watching %s", strings.Join(dirs, ", "))
+
+	files := snapshot(dirs)
+	var changed []string
+	var lastChange time.Time
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		next := snapshot(dirs)
+		if diff := changes(files, next); len(diff) > 0 {
+			files = next
+			changed = append(changed, diff...)
+			lastChange = time.Now()
+			continue
+		}
+
+		if len(changed) == 0 || time.Since(lastChange) < debounce {
+			continue
+		}
+
+		slices.Sort(changed)
+		clog.Infof("changed %s, regenerating", strings.Join(slices.Compact(changed), ", "))
+		changed = nil
+
+		start := time.Now()
+		if next, err := regenerate(ctx, flags, patterns); err != nil {
+			clog.Errorf("failed to generate: %v", err)
+		} else {
+			dirs = next
+			clog.Infof("regenerated in %s", time.Since(start).Round(time.Millisecond))
+		}
+
+		// generated files may be written into the watched dirs, don't report them as changes
+		files = snapshot(dirs)
+	}
+}
+
+// regenerate resolves and generates targets in sync mode, returning the directories of their sources
+func regenerate(ctx context.Context, flags *pflag.FlagSet, patterns []string) ([]string, error) {
+	targets, packages, err := resolveTargets(ctx, flags, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range targets {
+		targets[i].Overwrite = writer.OverwriteSync
+	}
+
+	if _, err = implgen.Generate(ctx, flagsToOptions(flags, targets, packages)); err != nil {
+		return nil, err
+	}
+
+	return sourceDirs(targets), nil
+}
+
+// sourceDirs returns the sorted directories of the target sources
+func sourceDirs(targets []config.Target) []string {
+	dirs := make([]string, 0, len(targets))
+	for _, target := range targets {
+		dirs = append(dirs, filepath.Dir(target.Src))
+	}
+	slices.Sort(dirs)
+
+	return slices.Compact(dirs)
+}
+
+// snapshot returns modification times of Go files in dirs, tests excluded
+func snapshot(dirs []string) map[string]time.Time {
+	files := make(map[string]time.Time)
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			files[filepath.Join(dir, name)] = info.ModTime()
+		}
+	}
+
+	return files
+}
+
+// changes returns the files added, modified or removed between snapshots
+func changes(prev, next map[string]time.Time) []string {
+	var changed []string
+
+	for path, modTime := range next {
+		if prevModTime, ok := prev[path]; !ok || !prevModTime.Equal(modTime) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := next[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+
+	return changed
+}
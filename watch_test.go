@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const watchedStore = `package svc
+
+//implgen:generate dst=../impl
+type Store interface {
+	Load(key string) string
+}
+`
+
+func TestWatchPatterns(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "svc", "store.go")
+	if err := os.MkdirAll(filepath.Dir(src), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.25\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte(watchedStore), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	cmd := &cobra.Command{}
+	registerFlags(cmd)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watch(ctx, cmd.PersistentFlags(), []string{"./..."}, 10*time.Millisecond, 10*time.Millisecond)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	awaitGenerated(t, filepath.Join(dir, "impl"), "func (i *Implementation) Load(key string) string {")
+
+	// the generated implementation misses the new method, so the module doesn't compile until it is regenerated
+	store := strings.Replace(watchedStore, "\tLoad(key string) string\n", "\tLoad(key string) string\n\tPurge()\n", 1)
+	if err := os.WriteFile(src, []byte(store), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(src, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	awaitGenerated(t, filepath.Join(dir, "impl"), "func (i *Implementation) Purge() {")
+}
+
+// awaitGenerated waits for a Go file under dir containing want
+func awaitGenerated(t *testing.T, dir, want string) {
+	t.Helper()
+
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		found := false
+		_ = filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || filepath.Ext(path) != ".go" {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err == nil && strings.Contains(string(data), want) {
+				found = true
+			}
+			return nil
+		})
+		if found {
+			return
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatalf("no file under %s contains %q", dir, want)
+}
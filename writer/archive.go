@@ -0,0 +1,138 @@
+package writer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/not-for-prod/implgen/model"
+	"golang.org/x/tools/txtar"
+)
+
+// WriteConcatenated writes files one after another, each preceded by a `==> path <==` header line
+func WriteConcatenated(w io.Writer, files []model.File) error {
+	for i, file := range files {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "==> %s <==\n", file.Path); err != nil {
+			return err
+		}
+		if _, err := w.Write(file.Data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteTxtar writes files as a txtar archive, see golang.org/x/tools/txtar
+func WriteTxtar(w io.Writer, files []model.File) error {
+	archive := &txtar.Archive{}
+	for _, file := range files {
+		archive.Files = append(archive.Files, txtar.File{Name: file.Path, Data: file.Data})
+	}
+
+	_, err := w.Write(txtar.Format(archive))
+
+	return err
+}
+
+// WriteZip writes files as a zip archive
+func WriteZip(w io.Writer, files []model.File) error {
+	zw := zip.NewWriter(w)
+
+	for _, file := range files {
+		fw, err := zw.Create(file.Path)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", file.Path, err)
+		}
+		if _, err = fw.Write(file.Data); err != nil {
+			return fmt.Errorf("failed to add %s: %w", file.Path, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// WriteTarGz writes files as a gzip-compressed tar archive
+func WriteTarGz(w io.Writer, files []model.File) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for _, file := range files {
+		header := &tar.Header{
+			Name:     file.Path,
+			Mode:     0o644,
+			Size:     int64(len(file.Data)),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to add %s: %w", file.Path, err)
+		}
+		if _, err := tw.Write(file.Data); err != nil {
+			return fmt.Errorf("failed to add %s: %w", file.Path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+// RelFiles returns files with slash-separated paths relative to the common root of their package
+// directories, so that archives hold them regardless of where they are generated, e.g. outside of
+// the working one, and every path keeps the directory of its package
+func RelFiles(files []model.File) ([]model.File, error) {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		path, err := filepath.Abs(file.Path)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	root := ""
+	for i, path := range paths {
+		dir := filepath.Dir(path)
+		if root == "" {
+			root = filepath.Dir(dir)
+		}
+		for !within(root, dir) || root == dir {
+			if filepath.Dir(root) == root {
+				return nil, fmt.Errorf("%s has no common root with other files", files[i].Path)
+			}
+			root = filepath.Dir(root)
+		}
+	}
+
+	rel := make([]model.File, 0, len(files))
+
+	for i, file := range files {
+		path, err := filepath.Rel(root, paths[i])
+		if err != nil {
+			return nil, err
+		}
+
+		rel = append(rel, model.File{Path: filepath.ToSlash(path), Data: file.Data})
+	}
+
+	return rel, nil
+}
+
+// within reports whether the absolute path is inside the absolute dir
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
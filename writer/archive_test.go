@@ -0,0 +1,90 @@
+package writer_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+	"github.com/not-for-prod/implgen/writer"
+	"golang.org/x/tools/txtar"
+)
+
+func TestRelFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{
+			name:  "single dir",
+			paths: []string{"out/impl/a.go", "out/impl/b.go"},
+			want:  []string{"impl/a.go", "impl/b.go"},
+		},
+		{
+			name:  "single file",
+			paths: []string{"out/impl/a.go"},
+			want:  []string{"impl/a.go"},
+		},
+		{
+			name:  "nested dirs",
+			paths: []string{"out/impl/a.go", "out/impl/mock/b.go", "out/other/c.go"},
+			want:  []string{"impl/a.go", "impl/mock/b.go", "other/c.go"},
+		},
+		{
+			name:  "package dir of the root",
+			paths: []string{"out/impl/a.go", "out/b.go"},
+			want:  []string{"out/impl/a.go", "out/b.go"},
+		},
+		{
+			name:  "outside of the working dir",
+			paths: []string{"../test/impl/a.go", "../test/impl/b_test.go"},
+			want:  []string{"impl/a.go", "impl/b_test.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make([]model.File, 0, len(tt.paths))
+			for _, path := range tt.paths {
+				files = append(files, model.File{Path: filepath.FromSlash(path)})
+			}
+
+			rel, err := writer.RelFiles(files)
+			if err != nil {
+				t.Fatalf("RelFiles() error = %v", err)
+			}
+
+			got := make([]string, 0, len(rel))
+			for _, file := range rel {
+				got = append(got, file.Path)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("RelFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTxtar(t *testing.T) {
+	files := []model.File{
+		{Path: "a.go", Data: []byte("package a\n")},
+		{Path: "mock/b.go", Data: []byte("package mock\n")},
+	}
+
+	var buf bytes.Buffer
+	if err := writer.WriteTxtar(&buf, files); err != nil {
+		t.Fatalf("WriteTxtar() error = %v", err)
+	}
+
+	archive := txtar.Parse(buf.Bytes())
+	if len(archive.Files) != len(files) {
+		t.Fatalf("WriteTxtar() wrote %d files, want %d", len(archive.Files), len(files))
+	}
+	for i, file := range archive.Files {
+		if file.Name != files[i].Path || !bytes.Equal(file.Data, files[i].Data) {
+			t.Errorf("WriteTxtar() file %d = %s %q, want %s %q", i, file.Name, file.Data, files[i].Path, files[i].Data)
+		}
+	}
+}
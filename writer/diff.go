@@ -0,0 +1,197 @@
+package writer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around changes in diff hunks
+const diffContext = 3
+
+// maxDiffCells caps the LCS matrix of diffLines, an int per pair of old and new lines
+// besides their common prefix and suffix, 4M cells take 32MB. Larger changes are diffed
+// as a full replacement
+const maxDiffCells = 1 << 22
+
+// edit is a line of the diff, op is ' ', '-' or '+'
+type edit struct {
+	op   byte
+	line string
+}
+
+// Diff returns the unified diff of old and new file contents, nil when they are equal.
+// An empty oldPath stands for a new file, diffed against /dev/null
+func Diff(oldPath, newPath string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+
+	edits := diffLines(splitLines(old), splitLines(new))
+
+	var buf bytes.Buffer
+
+	if oldPath == "" {
+		fmt.Fprintf(&buf, "--- /dev/null\n")
+	} else {
+		fmt.Fprintf(&buf, "--- a/%s\n", oldPath)
+	}
+	fmt.Fprintf(&buf, "+++ b/%s\n", newPath)
+
+	for start := 0; start < len(edits); {
+		// skip unchanged lines up to the hunk context
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+
+		// extend the hunk while changes are closer than twice the context
+		last := first
+		for i := first; i < len(edits); i++ {
+			if edits[i].op != ' ' {
+				last = i
+			} else if i-last > 2*diffContext {
+				break
+			}
+		}
+
+		from := max(first-diffContext, start)
+		to := min(last+diffContext+1, len(edits))
+
+		writeHunk(&buf, edits, from, to)
+		start = to
+	}
+
+	return buf.Bytes()
+}
+
+// writeHunk writes edits[from:to] as a hunk with its header
+func writeHunk(buf *bytes.Buffer, edits []edit, from, to int) {
+	oldLine, newLine := 1, 1
+	for _, e := range edits[:from] {
+		if e.op != '+' {
+			oldLine++
+		}
+		if e.op != '-' {
+			newLine++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	for _, e := range edits[from:to] {
+		if e.op != '+' {
+			oldCount++
+		}
+		if e.op != '-' {
+			newCount++
+		}
+	}
+
+	// empty ranges start at the line before them
+	if oldCount == 0 {
+		oldLine--
+	}
+	if newCount == 0 {
+		newLine--
+	}
+
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+	for _, e := range edits[from:to] {
+		buf.WriteByte(e.op)
+		buf.WriteString(e.line)
+		buf.WriteByte('\n')
+	}
+}
+
+// diffLines returns the edits turning old lines into new ones using their longest common subsequence,
+// or removing the changed old lines and adding the new ones when the subsequence is too costly to find
+func diffLines(old, new []string) []edit {
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix && old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]edit, 0, len(old)+len(new))
+	for _, line := range old[:prefix] {
+		edits = append(edits, edit{op: ' ', line: line})
+	}
+
+	oldChanged, newChanged := old[prefix:len(old)-suffix], new[prefix:len(new)-suffix]
+	if len(oldChanged)*len(newChanged) > maxDiffCells {
+		for _, line := range oldChanged {
+			edits = append(edits, edit{op: '-', line: line})
+		}
+		for _, line := range newChanged {
+			edits = append(edits, edit{op: '+', line: line})
+		}
+	} else {
+		edits = append(edits, lcsEdits(oldChanged, newChanged)...)
+	}
+
+	for _, line := range old[len(old)-suffix:] {
+		edits = append(edits, edit{op: ' ', line: line})
+	}
+
+	return edits
+}
+
+// lcsEdits returns the edits turning old lines into new ones using their longest common subsequence,
+// taking len(old)*len(new) memory
+func lcsEdits(old, new []string) []edit {
+	// lcs[i][j] is the length of the longest common subsequence of old[i:] and new[j:]
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]edit, 0, len(old)+len(new))
+	i, j := 0, 0
+
+	for i < len(old) && j < len(new) {
+		switch {
+		case old[i] == new[j]:
+			edits = append(edits, edit{op: ' ', line: old[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{op: '-', line: old[i]})
+			i++
+		default:
+			edits = append(edits, edit{op: '+', line: new[j]})
+			j++
+		}
+	}
+	for ; i < len(old); i++ {
+		edits = append(edits, edit{op: '-', line: old[i]})
+	}
+	for ; j < len(new); j++ {
+		edits = append(edits, edit{op: '+', line: new[j]})
+	}
+
+	return edits
+}
+
+// splitLines splits data into lines without line terminators
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
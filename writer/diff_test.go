@@ -0,0 +1,193 @@
+package writer_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/not-for-prod/implgen/writer"
+)
+
+// numbered returns the lines from to to inclusive, every line holding its number
+func numbered(from, to int) []string {
+	lines := make([]string, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		lines = append(lines, fmt.Sprint(i))
+	}
+
+	return lines
+}
+
+// text joins lines into the file contents
+func text(lines ...[]string) string {
+	var all []string
+	for _, l := range lines {
+		all = append(all, l...)
+	}
+	if len(all) == 0 {
+		return ""
+	}
+
+	return strings.Join(all, "\n") + "\n"
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldPath string
+		old     string
+		new     string
+		want    string
+	}{
+		{
+			name: "equal",
+			old:  text(numbered(1, 3)),
+			new:  text(numbered(1, 3)),
+			want: "",
+		},
+		{
+			name: "new file",
+			new:  "a\nb\n",
+			want: `--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+		{
+			name:    "removed contents",
+			oldPath: "old.go",
+			old:     "a\nb\n",
+			want: `--- a/old.go
++++ b/new.go
+@@ -1,2 +0,0 @@
+-a
+-b
+`,
+		},
+		{
+			name:    "pure insertion",
+			oldPath: "old.go",
+			old:     text(numbered(1, 10)),
+			new:     text(numbered(1, 5), []string{"x"}, numbered(6, 10)),
+			want: `--- a/old.go
++++ b/new.go
+@@ -3,6 +3,7 @@
+ 3
+ 4
+ 5
++x
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name:    "pure deletion",
+			oldPath: "old.go",
+			old:     text(numbered(1, 10)),
+			new:     text(numbered(1, 4), numbered(6, 10)),
+			want: `--- a/old.go
++++ b/new.go
+@@ -2,7 +2,6 @@
+ 2
+ 3
+ 4
+-5
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name:    "separate hunks",
+			oldPath: "old.go",
+			old:     text(numbered(1, 20)),
+			new:     text(numbered(1, 1), []string{"x"}, numbered(3, 17), []string{"y"}, numbered(19, 20)),
+			want: `--- a/old.go
++++ b/new.go
+@@ -1,5 +1,5 @@
+ 1
+-2
++x
+ 3
+ 4
+ 5
+@@ -15,6 +15,6 @@
+ 15
+ 16
+ 17
+-18
++y
+ 19
+ 20
+`,
+		},
+		{
+			name:    "merged hunks",
+			oldPath: "old.go",
+			old:     text(numbered(1, 20)),
+			new:     text(numbered(1, 4), []string{"x"}, numbered(6, 10), []string{"y"}, numbered(12, 20)),
+			want: `--- a/old.go
++++ b/new.go
+@@ -2,13 +2,13 @@
+ 2
+ 3
+ 4
+-5
++x
+ 6
+ 7
+ 8
+ 9
+ 10
+-11
++y
+ 12
+ 13
+ 14
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := writer.Diff(tt.oldPath, "new.go", []byte(tt.old), []byte(tt.new))
+			if string(got) != tt.want {
+				t.Errorf("Diff():\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffFullReplacement(t *testing.T) {
+	// too many changed lines to find their longest common subsequence
+	const n = 1 << 11
+	old := numbered(1, n+1)
+	new := make([]string, 0, n+3)
+	new = append(new, "first")
+	new = append(new, numbered(2, n+1)...)
+	new = append(new, "x", "last")
+
+	got := string(writer.Diff("old.go", "new.go", []byte(text(old)), []byte(text(new))))
+
+	// the first and the last lines differ, so no line is left out of the replacement
+	header := fmt.Sprintf("@@ -1,%d +1,%d @@\n", n+1, n+3)
+	if !strings.Contains(got, header) {
+		t.Fatalf("Diff() lacks the %q hunk header:\n%.200s", header, got)
+	}
+
+	removed, added := 0, 0
+	for _, line := range strings.Split(got, "\n")[3:] {
+		switch {
+		case strings.HasPrefix(line, "-"):
+			removed++
+		case strings.HasPrefix(line, "+"):
+			added++
+		}
+	}
+	if removed != n+1 || added != n+3 {
+		t.Errorf("Diff() removes %d and adds %d lines, want %d and %d", removed, added, n+1, n+3)
+	}
+}
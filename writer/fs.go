@@ -0,0 +1,220 @@
+package writer
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/not-for-prod/implgen/model"
+)
+
+// FS is the file system generated files are written to
+type FS interface {
+	Stat(name string) (fs.FileInfo, error)
+	ReadFile(name string) ([]byte, error)
+	MkdirAll(path string, perm fs.FileMode) error
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// OS is the FS of the operating system
+type OS struct{}
+
+func (OS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (OS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (OS) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (OS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+// Memory is an in-memory FS, safe for concurrent use
+type Memory struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+	dirs  map[string]bool
+}
+
+// NewMemory creates an empty Memory
+func NewMemory() *Memory {
+	return &Memory{files: make(map[string][]byte), dirs: make(map[string]bool)}
+}
+
+func (m *Memory) Stat(name string) (fs.FileInfo, error) {
+	name = filepath.Clean(name)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if data, ok := m.files[name]; ok {
+		return fileInfo{name: filepath.Base(name), size: int64(len(data))}, nil
+	}
+	if m.dirs[name] {
+		return fileInfo{name: filepath.Base(name), dir: true}, nil
+	}
+
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+func (m *Memory) ReadFile(name string) ([]byte, error) {
+	name = filepath.Clean(name)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return bytes.Clone(data), nil
+}
+
+func (m *Memory) MkdirAll(path string, _ fs.FileMode) error {
+	path = filepath.Clean(path)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for dir := path; !m.dirs[dir]; dir = filepath.Dir(dir) {
+		if _, ok := m.files[dir]; ok {
+			return &fs.PathError{Op: "mkdir", Path: dir, Err: syscall.ENOTDIR}
+		}
+
+		m.dirs[dir] = true
+	}
+
+	return nil
+}
+
+func (m *Memory) WriteFile(name string, data []byte, _ fs.FileMode) error {
+	name = filepath.Clean(name)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.dirs[name] {
+		return &fs.PathError{Op: "open", Path: name, Err: syscall.EISDIR}
+	}
+
+	m.files[name] = bytes.Clone(data)
+
+	return nil
+}
+
+// Files returns the files written sorted by path
+func (m *Memory) Files() []model.File {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files := make([]model.File, 0, len(m.files))
+	for path, data := range m.files {
+		files = append(files, model.File{Path: path, Data: bytes.Clone(data)})
+	}
+
+	slices.SortFunc(files, func(a, b model.File) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return files
+}
+
+// Overlay is an FS reading from Memory written files first and from the base FS otherwise,
+// all writes go to Memory so the base FS is never modified, e.g. for dry runs
+type Overlay struct {
+	base  FS
+	upper *Memory
+}
+
+// NewOverlay creates an Overlay over base
+func NewOverlay(base FS) *Overlay {
+	return &Overlay{base: base, upper: NewMemory()}
+}
+
+func (o *Overlay) Stat(name string) (fs.FileInfo, error) {
+	info, err := o.upper.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.base.Stat(name)
+	}
+
+	return info, err
+}
+
+func (o *Overlay) ReadFile(name string) ([]byte, error) {
+	data, err := o.upper.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.base.ReadFile(name)
+	}
+
+	return data, err
+}
+
+func (o *Overlay) MkdirAll(path string, perm fs.FileMode) error {
+	return o.upper.MkdirAll(path, perm)
+}
+
+func (o *Overlay) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return o.upper.WriteFile(name, data, perm)
+}
+
+// Files returns the files written over the base FS sorted by path
+func (o *Overlay) Files() []model.File {
+	return o.upper.Files()
+}
+
+// Diff returns unified diffs of the files written over the base FS against their base contents,
+// files missing in the base FS are diffed against /dev/null
+func (o *Overlay) Diff() ([]byte, error) {
+	var buf bytes.Buffer
+
+	for _, file := range o.upper.Files() {
+		base, err := o.base.ReadFile(file.Path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		oldPath := file.Path
+		if err != nil {
+			oldPath = ""
+		}
+
+		buf.Write(Diff(oldPath, file.Path, base, file.Data))
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fileInfo describes Memory files and directories
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() any           { return nil }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o755
+	}
+
+	return 0o644
+}
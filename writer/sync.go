@@ -0,0 +1,227 @@
+package writer
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"maps"
+	"slices"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// merge appends the top-level declarations of generated missing in existing along with
+// their imports, and the missing fields of structs declared by both. Declarations present
+// in existing are kept as is otherwise, so hand-written method bodies are never clobbered;
+// changed reports whether anything was appended
+func merge(path string, existing, generated []byte) (data []byte, changed bool, err error) {
+	fset := token.NewFileSet()
+
+	existingFile, err := parser.ParseFile(fset, path, existing, parser.ParseComments)
+	if err != nil {
+		return nil, false, err
+	}
+	generatedFile, err := parser.ParseFile(fset, path, generated, parser.ParseComments)
+	if err != nil {
+		return nil, false, err
+	}
+
+	present := make(map[string]bool)
+	for _, decl := range existingFile.Decls {
+		for _, key := range declKeys(decl) {
+			present[key] = true
+		}
+	}
+
+	buf := bytes.NewBuffer(mergeStructs(fset, existingFile, existing, generatedFile, generated))
+	changed = !bytes.Equal(buf.Bytes(), existing)
+
+	for _, decl := range generatedFile.Decls {
+		keys := declKeys(decl)
+		if len(keys) == 0 || slices.ContainsFunc(keys, func(key string) bool { return present[key] }) {
+			continue
+		}
+
+		start := decl.Pos()
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Doc != nil {
+				start = decl.Doc.Pos()
+			}
+		case *ast.GenDecl:
+			if decl.Doc != nil {
+				start = decl.Doc.Pos()
+			}
+		}
+
+		buf.WriteString("\n")
+		buf.Write(generated[fset.Position(start).Offset:fset.Position(decl.End()).Offset])
+		buf.WriteString("\n")
+		changed = true
+	}
+
+	if !changed {
+		return existing, false, nil
+	}
+
+	mergedFile, err := parser.ParseFile(fset, path, buf.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, spec := range generatedFile.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		astutil.AddNamedImport(fset, mergedFile, name, importPath)
+	}
+
+	out := bytes.Buffer{}
+	if err = format.Node(&out, fset, mergedFile); err != nil {
+		return nil, false, err
+	}
+
+	// drop imports of the generated file unused by the merged one
+	data, err = Format(path, out.Bytes())
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// mergeStructs returns existing with the fields of generated structs missing in the existing
+// structs of the same name inserted before their closing braces
+func mergeStructs(fset *token.FileSet, existingFile *ast.File, existing []byte, generatedFile *ast.File, generated []byte) []byte {
+	structs := make(map[string]*ast.StructType)
+	ast.Inspect(existingFile, func(node ast.Node) bool {
+		if spec, ok := node.(*ast.TypeSpec); ok {
+			if structType, ok := spec.Type.(*ast.StructType); ok {
+				structs[spec.Name.Name] = structType
+			}
+		}
+		return true
+	})
+
+	// missing fields are inserted before the closing brace offsets of existing structs
+	insertions := make(map[int][]byte)
+
+	ast.Inspect(generatedFile, func(node ast.Node) bool {
+		spec, ok := node.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		generatedStruct, ok := spec.Type.(*ast.StructType)
+		existingStruct, found := structs[spec.Name.Name]
+		if !ok || !found {
+			return false
+		}
+
+		present := make(map[string]bool)
+		for _, field := range existingStruct.Fields.List {
+			present[fieldKey(field)] = true
+		}
+
+		offset := fset.Position(existingStruct.Fields.Closing).Offset
+		for _, field := range generatedStruct.Fields.List {
+			if present[fieldKey(field)] {
+				continue
+			}
+
+			start := field.Pos()
+			if field.Doc != nil {
+				start = field.Doc.Pos()
+			}
+
+			insertions[offset] = append(insertions[offset], generated[fset.Position(start).Offset:fset.Position(field.End()).Offset]...)
+			insertions[offset] = append(insertions[offset], '\n')
+		}
+
+		return false
+	})
+
+	data := bytes.Clone(existing)
+
+	// insert from the end so that the remaining offsets stay valid
+	offsets := slices.Sorted(maps.Keys(insertions))
+	slices.Reverse(offsets)
+	for _, offset := range offsets {
+		data = slices.Insert(data, offset, insertions[offset]...)
+	}
+
+	return data
+}
+
+// fieldKey returns the name a struct field is matched by, embedded fields are matched by their type
+func fieldKey(field *ast.Field) string {
+	if len(field.Names) == 0 {
+		return exprString(field.Type)
+	}
+
+	return field.Names[0].Name
+}
+
+// declKeys returns the names a top-level declaration is matched by, methods are
+// qualified with their receiver type name, imports have no keys
+func declKeys(decl ast.Decl) []string {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		if decl.Recv == nil || len(decl.Recv.List) == 0 {
+			return []string{decl.Name.Name}
+		}
+
+		return []string{receiverName(decl.Recv.List[0].Type) + "." + decl.Name.Name}
+	case *ast.GenDecl:
+		var keys []string
+
+		for _, spec := range decl.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				keys = append(keys, spec.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range spec.Names {
+					// blank declarations, e.g. interface assertions, are matched by their type
+					if name.Name == "_" && spec.Type != nil {
+						keys = append(keys, "_."+exprString(spec.Type))
+					} else {
+						keys = append(keys, name.Name)
+					}
+				}
+			}
+		}
+
+		return keys
+	}
+
+	return nil
+}
+
+// receiverName returns the receiver base type name, e.g. Impl for *Impl[T]
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(expr.X)
+	case *ast.IndexExpr:
+		return receiverName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+
+	return ""
+}
+
+// exprString returns the source text of the expression
+func exprString(expr ast.Expr) string {
+	buf := bytes.Buffer{}
+	_ = format.Node(&buf, token.NewFileSet(), expr)
+
+	return buf.String()
+}
@@ -0,0 +1,215 @@
+package writer_test
+
+import (
+	"testing"
+
+	"github.com/not-for-prod/implgen/model"
+	"github.com/not-for-prod/implgen/writer"
+)
+
+func TestSync(t *testing.T) {
+	const path = "impl/impl.go"
+
+	tests := []struct {
+		name      string
+		existing  string
+		generated string
+		want      string
+		written   bool
+	}{
+		{
+			name: "kept bodies",
+			existing: `package impl
+
+type Impl struct{}
+
+func (i *Impl) Do() error {
+	return nil
+}
+`,
+			generated: `package impl
+
+type Impl struct{}
+
+func (i *Impl) Do() error {
+	panic("implement me")
+}
+`,
+			want: `package impl
+
+type Impl struct{}
+
+func (i *Impl) Do() error {
+	return nil
+}
+`,
+			written: false,
+		},
+		{
+			name: "appended methods",
+			existing: `package impl
+
+type Impl struct{}
+
+func (i *Impl) Do() error {
+	return nil
+}
+`,
+			generated: `package impl
+
+type Impl struct{}
+
+func (i *Impl) Do() error {
+	panic("implement me")
+}
+
+// Undo reverts Do.
+func (i *Impl) Undo() error {
+	panic("implement me")
+}
+`,
+			want: `package impl
+
+type Impl struct{}
+
+func (i *Impl) Do() error {
+	return nil
+}
+
+// Undo reverts Do.
+func (i *Impl) Undo() error {
+	panic("implement me")
+}
+`,
+			written: true,
+		},
+		{
+			name: "appended fields",
+			existing: `package impl
+
+type Impl struct {
+	// name is set by hand
+	name string
+}
+`,
+			generated: `package impl
+
+type Impl struct {
+	name string
+	// count is a new dependency
+	count int
+}
+`,
+			want: `package impl
+
+type Impl struct {
+	// name is set by hand
+	name string
+	// count is a new dependency
+	count int
+}
+`,
+			written: true,
+		},
+		{
+			name: "new imports",
+			existing: `package impl
+
+import "strings"
+
+type Impl struct{}
+
+func (i *Impl) Name() string {
+	return strings.ToUpper("impl")
+}
+`,
+			generated: `package impl
+
+import (
+	"errors"
+	"strings"
+)
+
+type Impl struct{}
+
+func (i *Impl) Name() string {
+	return strings.ToLower("impl")
+}
+
+func (i *Impl) Do() error {
+	return errors.New("implement me")
+}
+`,
+			want: `package impl
+
+import (
+	"errors"
+	"strings"
+)
+
+type Impl struct{}
+
+func (i *Impl) Name() string {
+	return strings.ToUpper("impl")
+}
+
+func (i *Impl) Do() error {
+	return errors.New("implement me")
+}
+`,
+			written: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := writer.NewMemory()
+			if err := fs.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			written, err := writer.NewCommand(false, writer.OverwriteSync, fs).Execute(
+				[]model.File{{Path: path, Data: []byte(tt.generated)}},
+			)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := len(written) > 0; got != tt.written {
+				t.Errorf("Execute() written = %v, want %v", written, tt.written)
+			}
+
+			got, err := fs.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("synced file:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncNewFile(t *testing.T) {
+	const path = "impl/impl.go"
+
+	fs := writer.NewMemory()
+	data := "package impl\n\ntype Impl struct{}\n"
+
+	written, err := writer.NewCommand(false, writer.OverwriteSync, fs).Execute(
+		[]model.File{{Path: path, Data: []byte(data)}},
+	)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(written) != 1 {
+		t.Fatalf("Execute() written = %v, want [%s]", written, path)
+	}
+
+	got, err := fs.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != data {
+		t.Errorf("written file:\n%s\nwant:\n%s", got, data)
+	}
+}
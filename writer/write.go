@@ -1,38 +1,69 @@
 package writer
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"go/format"
-	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/manifoldco/promptui"
 	"github.com/not-for-prod/implgen/model"
-	"github.com/not-for-prod/implgen/pkg/clog"
 
 	importsTool "golang.org/x/tools/imports"
 )
 
+const (
+	// OverwriteNever keeps existing files
+	OverwriteNever = "never"
+	// OverwriteAlways replaces existing files
+	OverwriteAlways = "always"
+	// OverwritePrompt asks whether to replace every existing file
+	OverwritePrompt = "prompt"
+	// OverwriteSync appends declarations missing in existing files, keeping the present ones as is
+	OverwriteSync = "sync"
+)
+
 type Command struct {
 	// Enable verbose logging
 	verbose bool
+	// overwritePolicy is the policy for existing files, see Overwrite* constants.
+	// If empty, existing files are kept unless verbose, which prompts for them
+	overwritePolicy string
+	// fs is the file system files are written to
+	fs FS
 }
 
-func NewCommand(verbose bool) *Command {
-	return &Command{verbose: verbose}
+// NewCommand creates a new Command writing to fsys, OS when it is nil
+func NewCommand(verbose bool, overwritePolicy string, fsys FS) *Command {
+	if fsys == nil {
+		fsys = OS{}
+	}
+
+	return &Command{verbose: verbose, overwritePolicy: overwritePolicy, fs: fsys}
 }
 
 func (w *Command) overwrite(path string) bool {
-	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+	if _, err := w.fs.Stat(path); errors.Is(err, fs.ErrNotExist) {
 		return true
 	}
 
-	if !w.verbose {
+	// tests are edited by hand right after generation, never overwrite them
+	if strings.HasSuffix(path, "_test.go") {
+		return false
+	}
+
+	switch w.overwritePolicy {
+	case OverwriteAlways:
+		return true
+	case OverwriteNever, OverwriteSync:
 		return false
+	case "":
+		if !w.verbose {
+			return false
+		}
 	}
 
 	keep := "don't overwrite " + path
@@ -55,35 +86,45 @@ func (w *Command) overwrite(path string) bool {
 	return false
 }
 
-// WriteToFile writes r to the file with path
-func (w *Command) writeToFile(path string, r io.Reader) error {
+// writeBytesToFile writes data to the file with path according to the overwrite policy,
+// written reports whether the file was written
+func (w *Command) writeBytesToFile(path string, data []byte) (written bool, err error) {
+	if w.overwritePolicy == OverwriteSync && filepath.Ext(path) == ".go" && !strings.HasSuffix(path, "_test.go") {
+		existing, err := w.fs.ReadFile(path)
+		if err == nil {
+			merged, changed, err := merge(path, existing, data)
+			if err != nil || !changed {
+				return false, err
+			}
+
+			return true, w.createFile(path, merged)
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return false, err
+		}
+	}
+
 	if !w.overwrite(path) {
-		return nil
+		return false, nil
 	}
 
+	return true, w.createFile(path, data)
+}
+
+// createFile creates or truncates the file with path and writes data to it
+func (w *Command) createFile(path string, data []byte) error {
 	dir := filepath.Dir(path)
 	if dir != "" {
-		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		if err := w.fs.MkdirAll(dir, os.ModePerm); err != nil {
 			return err
 		}
 	}
 
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = file.Close() }()
-
-	_, err = io.Copy(file, r)
-	return err
+	return w.fs.WriteFile(path, data, 0o644)
 }
 
-func (w *Command) writeBytesToFile(path string, data []byte) error {
-	return w.writeToFile(path, bytes.NewReader(data))
-}
-
-// writeGoBytesToFile WriteBytesToFile (that are actually go code)  but before makes `goimports -w ...` && `go fmt ...`
-func (w *Command) writeGoBytesToFile(path string, data []byte) error {
+// Format makes `goimports -w ...` && `go fmt ...` for go code data to be written into path
+func Format(path string, data []byte) ([]byte, error) {
 	var err error
 
 	// goimports -w ...
@@ -93,30 +134,59 @@ func (w *Command) writeGoBytesToFile(path string, data []byte) error {
 		},
 	)
 	if err != nil {
-		clog.Fatalf(err.Error())
+		return nil, err
 	}
 
 	// go fmt ...
-	data, err = format.Source(data)
-	if err != nil {
-		clog.Fatalf(err.Error())
-	}
+	return format.Source(data)
+}
 
-	err = w.writeBytesToFile(path, data)
+// writeGoBytesToFile WriteBytesToFile (that are actually go code)  but before makes `goimports -w ...` && `go fmt ...`
+func (w *Command) writeGoBytesToFile(path string, data []byte) (bool, error) {
+	data, err := Format(path, data)
 	if err != nil {
-		return err
+		return false, fmt.Errorf("failed to format %s: %w", path, err)
 	}
 
-	return nil
+	return w.writeBytesToFile(path, data)
+}
+
+// Execute formats and writes files, returning paths of the written ones
+func (w *Command) Execute(files []model.File) ([]string, error) {
+	return w.write(files, w.writeGoBytesToFile)
+}
+
+// Write writes files formatted beforehand with Format, e.g. concurrently,
+// returning paths of the written ones
+func (w *Command) Write(files []model.File) ([]string, error) {
+	return w.write(files, w.writeBytesToFile)
 }
 
-func (w *Command) Execute(files []model.File) error {
+func (w *Command) write(files []model.File, writeFile func(path string, data []byte) (bool, error)) ([]string, error) {
+	if err := w.validate(); err != nil {
+		return nil, err
+	}
+
+	var written []string
+
 	for _, file := range files {
-		err := w.writeGoBytesToFile(file.Path, file.Data)
+		ok, err := writeFile(file.Path, file.Data)
 		if err != nil {
-			return err
+			return written, err
+		}
+		if ok {
+			written = append(written, file.Path)
 		}
 	}
 
-	return nil
+	return written, nil
+}
+
+func (w *Command) validate() error {
+	switch w.overwritePolicy {
+	case "", OverwriteNever, OverwriteAlways, OverwritePrompt, OverwriteSync:
+		return nil
+	default:
+		return fmt.Errorf("unknown overwrite policy %q", w.overwritePolicy)
+	}
 }